@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mcg-dallgow/mcg-display/services"
+	"github.com/mcg-dallgow/mcg-display/services/webuntis"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+// events by date, taking the same parameters as the display
+func EventsApi(c echo.Context) error {
+	// display names are public, so they never unlock staff events through the API
+	events, err := getEvents(c, PublicAudience, StudentAudience)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Success: true,
+		Result:  events,
+	})
+}
+
+// exams by date, taking the same parameters as the display
+func ExamsApi(c echo.Context) error {
+	// exams are published to students like in the exam feed, staff events are never returned
+	events, err := getEvents(c, StudentAudience, StudentAudience)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	exams := make(map[string][]Event)
+	for date, dayEvents := range events {
+		exams[date] = []Event{}
+		for _, event := range dayEvents {
+			if slices.Contains(event.Sources, ExamSource) {
+				exams[date] = append(exams[date], event)
+			}
+		}
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Success: true,
+		Result:  exams,
+	})
+}
+
+// teachers or classes that can be selected, e.g. "?type=teacher"
+func PersonsApi(c echo.Context) error {
+	personType := webuntis.PersonType(c.QueryParam("type"))
+	if personType == "" {
+		personType = webuntis.TypeTeacher
+	}
+
+	persons, err := services.GetPersons(personType)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Success: true,
+		Result:  persons,
+	})
+}
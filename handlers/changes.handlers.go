@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mcg-dallgow/mcg-display/services"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+func Changes(c echo.Context) error {
+	since := c.QueryParam("since")
+
+	// if no date is given, return the changes of the last week
+	sinceDate := time.Now().Add(-7 * 24 * time.Hour)
+	if since != "" {
+		var err error
+		sinceDate, err = time.Parse("2006-01-02", since)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, Response{
+				Success: false,
+				Message: "error: since date is invalid",
+			})
+		}
+	}
+
+	// changes contain whole events, so they are limited like the other public routes
+	filter, err := services.GetEventFilter(c.QueryParam("display"), "", "", PublicAudience)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	changes, err := services.GetChanges(sinceDate, services.LimitAudience(filter, StudentAudience))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Success: true,
+		Result:  changes,
+	})
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mcg-dallgow/mcg-display/services"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+func Conflicts(c echo.Context) error {
+	start := c.QueryParam("start")
+	end := c.QueryParam("end")
+	days := c.QueryParam("days")
+
+	startDate, endDate, err := services.ParseDateRange(start, end, days)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	// conflicts contain whole events, so they are limited like the other public routes
+	filter, err := services.GetEventFilter(c.QueryParam("display"), "", "", PublicAudience)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	conflicts, err := services.GetConflicts(startDate, endDate, services.LimitAudience(filter, StudentAudience))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Success: true,
+		Result:  conflicts,
+	})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"net/url"
 
 	"github.com/labstack/echo/v4"
 	"github.com/mcg-dallgow/mcg-display/components"
@@ -11,36 +12,49 @@ import (
 )
 
 func Events(c echo.Context) error {
-	start := c.QueryParam("start")
-	end := c.QueryParam("end")
-	days := c.QueryParam("days")
-	teacher := c.QueryParam("teacher")
-	student := c.QueryParam("student")
-
-	startDate, endDate, err := services.ParseDateRange(start, end, days)
+	// screens show public events unless their display profile allows more
+	events, err := getEvents(c, PublicAudience, StaffAudience)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, Response{
 			Success: false,
 			Message: err.Error(),
 		})
 	}
-	var person string
-	var personType webuntis.PersonType
-	if teacher != "" {
-		person = teacher
-		personType = webuntis.TypeTeacher
-	} else {
-		person = student
-		personType = webuntis.TypeStudent
-	}
 
-	events, err := services.GetEvents(startDate, endDate, person, personType)
+	return c.HTML(http.StatusOK, services.RenderComponent(components.Events(events)))
+}
+
+// events of the query, the audience applies unless a display is given, but never exceeds the maximum
+func getEvents(c echo.Context, audience, maxAudience Audience) (events map[string][]Event, err error) {
+	start := c.QueryParam("start")
+	end := c.QueryParam("end")
+	days := c.QueryParam("days")
+	display := c.QueryParam("display")
+	include := c.QueryParam("include")
+	exclude := c.QueryParam("exclude")
+	params := c.QueryParams()
+
+	startDate, endDate, err := services.ParseDateRange(start, end, days)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, Response{
-			Success: false,
-			Message: err.Error(),
-		})
+		return events, err
+	}
+	resources := getResources(params)
+	filter, err := services.GetEventFilter(display, include, exclude, audience)
+	if err != nil {
+		return events, err
 	}
 
-	return c.HTML(http.StatusOK, services.RenderComponent(components.Events(events)))
+	return services.GetEvents(startDate, endDate, resources, services.LimitAudience(filter, maxAudience))
+}
+
+// get all teachers, students and classes selected in the query, e.g. "?class=10a&class=10b&teacher=Mül"
+func getResources(params url.Values) (resources []services.Resource) {
+	for _, personType := range []webuntis.PersonType{webuntis.TypeTeacher, webuntis.TypeStudent, webuntis.TypeClass} {
+		for _, name := range params[string(personType)] {
+			if name != "" {
+				resources = append(resources, services.Resource{Type: personType, Name: name})
+			}
+		}
+	}
+	return resources
 }
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mcg-dallgow/mcg-display/components"
+	"github.com/mcg-dallgow/mcg-display/services"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+func ExamPreview(c echo.Context) error {
+	id := c.QueryParam("id")
+	start := c.QueryParam("start")
+	end := c.QueryParam("end")
+	days := c.QueryParam("days")
+	titleTemplate := c.QueryParam("title")
+
+	examId, err := strconv.Atoi(id)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: "error: exam id is not a valid integer",
+		})
+	}
+	startDate, endDate, err := services.ParseDateRange(start, end, days)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	preview, err := services.GetExamPreview(examId, startDate, endDate, titleTemplate)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Success: true,
+		Result:  preview,
+	})
+}
+
+func ExamWorkload(c echo.Context) error {
+	issues, err := getExamWorkload(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Success: true,
+		Result:  issues,
+	})
+}
+
+func ExamWorkloadReport(c echo.Context) error {
+	issues, err := getExamWorkload(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.HTML(http.StatusOK, services.RenderComponent(components.WorkloadReport(services.GetWorkloadRules(), issues)))
+}
+
+func getExamWorkload(c echo.Context) (issues []services.WorkloadIssue, err error) {
+	start := c.QueryParam("start")
+	end := c.QueryParam("end")
+	days := c.QueryParam("days")
+
+	startDate, endDate, err := services.ParseDateRange(start, end, days)
+	if err != nil {
+		return issues, err
+	}
+	return services.GetExamWorkload(startDate, endDate)
+}
+
+func ExamSlots(c echo.Context) error {
+	slots, err := findExamSlots(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Success: true,
+		Result:  slots,
+	})
+}
+
+func ExamSlotsForm(c echo.Context) error {
+	group := c.QueryParam("group")
+	duration := c.QueryParam("duration")
+
+	// the empty form is shown until a class or course is given
+	var slots []services.Slot
+	var message string
+	if group != "" {
+		var err error
+		slots, err = findExamSlots(c)
+		if err != nil {
+			message = err.Error()
+		}
+	}
+
+	return c.HTML(http.StatusOK, services.RenderComponent(components.ExamSlots(group, duration, slots, message)))
+}
+
+func findExamSlots(c echo.Context) (slots []services.Slot, err error) {
+	group := c.QueryParam("group")
+	duration := c.QueryParam("duration")
+	start := c.QueryParam("start")
+	end := c.QueryParam("end")
+	days := c.QueryParam("days")
+
+	minutes, err := strconv.Atoi(duration)
+	if err != nil {
+		return slots, errors.New("error: duration is not a valid integer")
+	}
+	// exams are usually planned a few weeks ahead
+	if end == "" && days == "" {
+		days = "28"
+	}
+	startDate, endDate, err := services.ParseDateRange(start, end, days)
+	if err != nil {
+		return slots, err
+	}
+
+	return services.FindExamSlots(group, time.Duration(minutes)*time.Minute, startDate, endDate)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mcg-dallgow/mcg-display/services"
+	"github.com/mcg-dallgow/mcg-display/services/webuntis"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+// feed of all school events, e.g. "/ical/school.ics?display=foyer"
+func SchoolFeed(c echo.Context) error {
+	filter, err := services.GetEventFilter(c.QueryParam("display"), "", "", PublicAudience)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	// feeds without token can be subscribed by anyone, so staff events are only published in personal feeds
+	return sendFeed(c, "MCG Termine", nil, services.LimitAudience(filter, StudentAudience))
+}
+
+// feed of the events of one category, e.g. "/ical/category/exam.ics"
+func CategoryFeed(c echo.Context) error {
+	category, err := ParseEventCategory(getFeedParam(c, "category"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	// categories like exams are meant for students, but not for the public
+	filter, err := services.GetEventFilter(c.QueryParam("display"), category.Key(), "", StudentAudience)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	return sendFeed(c, "MCG "+category.String(), nil, services.LimitAudience(filter, StudentAudience))
+}
+
+// feed of the events of one class, e.g. "/ical/class/10a.ics"
+func ClassFeed(c echo.Context) error {
+	class := getFeedParam(c, "class")
+
+	resources := []services.Resource{{Type: webuntis.TypeClass, Name: class}}
+	return sendFeed(c, "MCG "+class, resources, services.EventFilter{Audience: StudentAudience})
+}
+
+// feed of the events of a teacher or student, identified by a secret token
+func PersonalFeed(c echo.Context) error {
+	token := getFeedParam(c, "token")
+
+	resource, err := services.GetFeedResource(token)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	filter := services.EventFilter{Audience: StudentAudience}
+	if resource.Type == webuntis.TypeTeacher {
+		filter.Audience = StaffAudience
+	}
+	return sendFeed(c, "MCG "+resource.Name, []services.Resource{resource}, filter)
+}
+
+func sendFeed(c echo.Context, name string, resources []services.Resource, filter services.EventFilter) error {
+	feed, err := services.GetFeed(name, resources, filter)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", []byte(feed))
+}
+
+// feed URLs may end with ".ics" for calendar apps expecting a file
+func getFeedParam(c echo.Context, name string) string {
+	return strings.TrimSuffix(c.Param(name), ".ics")
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mcg-dallgow/mcg-display/components"
+	"github.com/mcg-dallgow/mcg-display/services"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+func Search(c echo.Context) error {
+	query := c.QueryParam("q")
+
+	results, err := searchEvents(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.HTML(http.StatusOK, services.RenderComponent(components.Search(query, results)))
+}
+
+func SearchApi(c echo.Context) error {
+	results, err := searchEvents(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Success: true,
+		Result:  results,
+	})
+}
+
+func searchEvents(c echo.Context) (results []services.SearchResult, err error) {
+	query := c.QueryParam("q")
+	start := c.QueryParam("start")
+	end := c.QueryParam("end")
+	days := c.QueryParam("days")
+	display := c.QueryParam("display")
+
+	// without a date range the configured search horizon is used
+	if end == "" && days == "" {
+		days = strconv.Itoa(services.GetSearchHorizon())
+	}
+	startDate, endDate, err := services.ParseDateRange(start, end, days)
+	if err != nil {
+		return results, err
+	}
+	// anonymous searches only find public events, display profiles may widen them
+	filter, err := services.GetEventFilter(display, "", "", PublicAudience)
+	if err != nil {
+		return results, err
+	}
+
+	return services.SearchEvents(query, startDate, endDate, filter)
+}
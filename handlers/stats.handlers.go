@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mcg-dallgow/mcg-display/components"
+	"github.com/mcg-dallgow/mcg-display/services"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+func Stats(c echo.Context) error {
+	stats, err := getStatistics(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Success: true,
+		Result:  stats,
+	})
+}
+
+func StatsPage(c echo.Context) error {
+	stats, err := getStatistics(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.HTML(http.StatusOK, services.RenderComponent(components.Stats(stats)))
+}
+
+func getStatistics(c echo.Context) (stats services.Statistics, err error) {
+	start := c.QueryParam("start")
+	end := c.QueryParam("end")
+	days := c.QueryParam("days")
+
+	startDate, endDate, err := services.ParseDateRange(start, end, days)
+	if err != nil {
+		return stats, err
+	}
+	return services.GetStatistics(startDate, endDate)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/mcg-dallgow/mcg-display/handlers"
+	"github.com/mcg-dallgow/mcg-display/services"
 )
 
 func main() {
@@ -19,6 +20,45 @@ func main() {
 
 	// Routes
 	e.GET("/", handlers.Events)
+	e.GET("/search", handlers.Search)
+	e.GET("/exams/workload", handlers.ExamWorkloadReport)
+	e.GET("/exams/slots", handlers.ExamSlotsForm)
+	e.GET("/stats", handlers.StatsPage)
+	e.GET("/api/changes", handlers.Changes)
+	e.GET("/api/conflicts", handlers.Conflicts)
+	e.GET("/api/search", handlers.SearchApi)
+	e.GET("/api/stats", handlers.Stats)
+	e.GET("/api/exams/preview", handlers.ExamPreview)
+	e.GET("/api/exams/workload", handlers.ExamWorkload)
+	e.GET("/api/exams/slots", handlers.ExamSlots)
+
+	// Versioned JSON API
+	v1 := e.Group("/api/v1")
+	v1.GET("/events", handlers.EventsApi)
+	v1.GET("/exams", handlers.ExamsApi)
+	v1.GET("/persons", handlers.PersonsApi)
+
+	// iCalendar feeds
+	e.GET("/ical/school", handlers.SchoolFeed)
+	e.GET("/ical/school.ics", handlers.SchoolFeed)
+	e.GET("/ical/category/:category", handlers.CategoryFeed)
+	e.GET("/ical/class/:class", handlers.ClassFeed)
+	e.GET("/ical/personal/:token", handlers.PersonalFeed)
+
+	// Cache, configuration, history and background prefetching
+	services.InitCache()
+	services.InitCategoryRules()
+	services.InitSubjects()
+	services.InitRooms()
+	services.InitTeachers()
+	services.InitDisplays()
+	services.InitExamTemplates()
+	services.InitWorkloadRules()
+	services.InitSlots()
+	services.InitHistory()
+	services.InitNotifications()
+	services.InitFeeds()
+	services.StartScheduler()
 
 	// Start server
 	e.Logger.Fatal(e.Start(":9000"))
@@ -1,109 +1,311 @@
 package services
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
-	"slices"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/joho/godotenv"
 )
 
 const dateFormat string = "20060102"
-const timestampFormat string = "200601021504"
-const cacheBaseDir string = "./tmp/cache/"
+const defaultCacheDir string = "./tmp/cache/"
+
+// entries not written for this long are removed, e.g. days nobody views anymore
+const defaultCacheRetention time.Duration = 7 * 24 * time.Hour
+
+// data sources with their own cache validity
+const (
+	examsCache      string = "exams"
+	calendarCache   string = "calendar"
+	timetableCache  string = "timetable"
+	personalCache   string = "personal"
+	masterDataCache string = "masterdata"
+	// rendered feeds are valid as long as the events they contain
+	feedCache string = "feeds"
+)
+
+// default cache validity per data source, can be overwritten in .env
+var defaultCacheTTLs = map[string]time.Duration{
+	examsCache:      time.Hour,
+	calendarCache:   time.Hour,
+	timetableCache:  time.Hour,
+	personalCache:   time.Hour,
+	masterDataCache: 24 * time.Hour,
+}
+
+var cache CacheStore = &FileCache{Dir: defaultCacheDir}
+var cacheTTLs = maps.Clone(defaultCacheTTLs)
+var cacheRetention = defaultCacheRetention
 
-type Cache struct {
-	Name  string
-	Start time.Time
-	End   time.Time
+type CacheStore interface {
+	// load the data stored under the key and the time it was written
+	Load(key string) (data []byte, updated time.Time, err error)
+	Write(key string, data []byte) (err error)
+	Delete(key string) (err error)
+	// remove all entries written before the time
+	Cleanup(before time.Time) (err error)
 }
 
-func (cache *Cache) Load() (data []byte, err error) {
-	times := cache.getCachedTimes()
-	if len(times) == 0 {
-		return data, errors.New("error: there are no such caches")
+// configure the cache backend and validity from .env
+func InitCache() {
+	godotenv.Load()
+
+	switch os.Getenv("CACHE_BACKEND") {
+	case "memory":
+		SetCacheStore(NewMemoryCache())
+	default:
+		dir := os.Getenv("CACHE_DIR")
+		if dir == "" {
+			dir = defaultCacheDir
+		}
+		SetCacheStore(&FileCache{Dir: dir})
 	}
-	data, err = os.ReadFile(cache.getPath(times[0]))
-	if err != nil {
-		return data, err
+
+	for source, ttl := range defaultCacheTTLs {
+		SetCacheTTL(source, getEnvDuration("CACHE_TTL_"+strings.ToUpper(source), ttl))
 	}
-	return data, nil
+	cacheRetention = getEnvDuration("CACHE_RETENTION", defaultCacheRetention)
+}
+
+// remove cache entries that have not been written within the retention
+func CleanupCache() (err error) {
+	return cache.Cleanup(time.Now().Add(-cacheRetention))
+}
+
+func SetCacheStore(store CacheStore) {
+	cache = store
 }
 
-func (cache *Cache) Write(text []byte) (err error) {
-	if _, err = os.Stat(cache.getDir()); os.IsNotExist(err) {
-		os.MkdirAll(cache.getDir(), 0700)
+func SetCacheTTL(source string, ttl time.Duration) {
+	cacheTTLs[source] = ttl
+}
+
+// load cached data of a source into value if it is still valid
+func loadCached(source, key string, value any) (err error) {
+	updated, err := loadCachedData(source, key, value)
+	if err != nil {
+		return err
+	}
+	if time.Since(updated) > cacheTTLs[source] {
+		return errors.New("error: cache is not valid")
 	}
-	err = os.WriteFile(cache.getPath(time.Now()), text, 0644)
-	cache.cleanup()
+	return nil
+}
 
+// load cached data of a source into value regardless of its age
+func loadSnapshot(source, key string, value any) (err error) {
+	_, err = loadCachedData(source, key, value)
 	return err
 }
 
-func (cache *Cache) IsValid() bool {
-	times := cache.getCachedTimes()
-	// cache is invalid if there are no saved files
-	if len(times) == 0 {
-		return false
+func loadCachedData(source, key string, value any) (updated time.Time, err error) {
+	data, updated, err := cache.Load(source + "/" + key)
+	if err != nil {
+		return updated, err
 	}
-	// building current time from string to ignore timezones
-	now, _ := time.Parse(timestampFormat, time.Now().Format(timestampFormat))
-	// cache is valid if it is less than one hour old
-	return times[0].After(now.Add(-1 * time.Hour))
+	err = json.Unmarshal(data, value)
+	if err != nil {
+		// remove corrupted entries so that the data is fetched again
+		cache.Delete(source + "/" + key)
+		return updated, err
+	}
+	return updated, nil
+}
+
+func writeCached(source, key string, value any) (err error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return cache.Write(source+"/"+key, data)
+}
+
+// cache keeping all data in memory, used for tests and single-process deployments
+type MemoryCache struct {
+	mutex   sync.RWMutex
+	entries map[string]memoryCacheEntry
+}
+
+type memoryCacheEntry struct {
+	data    []byte
+	updated time.Time
 }
 
-func (cache *Cache) getDir() (dir string) {
-	return filepath.Join(cacheBaseDir, filepath.Base(cache.Name))
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]memoryCacheEntry)}
 }
 
-func (cache *Cache) getPathStrTime(timestamp string) (path string) {
-	file := fmt.Sprintf(
-		"%s-%s-%s-%s",
-		cache.Name,
-		cache.Start.Format(dateFormat),
-		cache.End.Format(dateFormat),
-		timestamp,
-	)
-	return filepath.Join(cache.getDir(), filepath.Base(file))
+func (cache *MemoryCache) Load(key string) (data []byte, updated time.Time, err error) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	entry, ok := cache.entries[key]
+	if !ok {
+		return data, updated, errors.New("error: there is no such cache")
+	}
+	return entry.data, entry.updated, nil
 }
 
-func (cache *Cache) getPath(timestamp time.Time) (path string) {
-	return cache.getPathStrTime(timestamp.Format(timestampFormat))
+func (cache *MemoryCache) Write(key string, data []byte) (err error) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.entries[key] = memoryCacheEntry{data: data, updated: time.Now()}
+	return nil
+}
+
+func (cache *MemoryCache) Delete(key string) (err error) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	delete(cache.entries, key)
+	return nil
 }
 
-func (cache *Cache) getCachedTimes() (timestamps []time.Time) {
-	matches, _ := filepath.Glob(cache.getPathStrTime("*"))
+func (cache *MemoryCache) Cleanup(before time.Time) (err error) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
 
-	for _, file := range matches {
-		timestamp, err := time.Parse(timestampFormat, strings.Split(filepath.Base(file), "-")[3])
-		if err == nil {
-			timestamps = append(timestamps, timestamp)
+	for key, entry := range cache.entries {
+		if entry.updated.Before(before) {
+			delete(cache.entries, key)
 		}
 	}
+	return nil
+}
+
+// cache storing every key as a JSON file inside a directory
+type FileCache struct {
+	Dir string
+}
+
+func (cache *FileCache) Load(key string) (data []byte, updated time.Time, err error) {
+	info, err := os.Stat(cache.getPath(key))
+	if err != nil {
+		return data, updated, errors.New("error: there is no such cache")
+	}
+	data, err = os.ReadFile(cache.getPath(key))
+	if err != nil {
+		return data, updated, err
+	}
+	return data, info.ModTime(), nil
+}
+
+func (cache *FileCache) Write(key string, data []byte) (err error) {
+	path := cache.getPath(key)
+	if _, err = os.Stat(filepath.Dir(path)); os.IsNotExist(err) {
+		os.MkdirAll(filepath.Dir(path), 0700)
+	}
+
+	// write to a temporary file first and rename it, so readers never see partially written files
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(file.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func (cache *FileCache) Delete(key string) (err error) {
+	err = os.Remove(cache.getPath(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
 
-	// sorts timestamps with the most recent being first
-	slices.SortFunc(timestamps, func(a, b time.Time) int {
-		if a.Before(b) {
-			return 1
+func (cache *FileCache) Cleanup(before time.Time) (err error) {
+	err = filepath.WalkDir(cache.Dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
 		}
-		if b.Before(a) {
-			return -1
+		// temporary files are left behind if the process stops while writing
+		if info, err := entry.Info(); err == nil && info.ModTime().Before(before) {
+			os.Remove(path)
 		}
-		return 0
+		return nil
 	})
-
-	return timestamps
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
 }
 
-func (cache *Cache) cleanup() {
-	times := cache.getCachedTimes()
-	if len(times) < 2 {
-		return
+func (cache *FileCache) getPath(key string) (path string) {
+	// keys may contain user input, so every part is reduced to a plain file name
+	parts := []string{cache.Dir}
+	for _, part := range strings.Split(key, "/") {
+		part = filepath.Base(part)
+		if part == "." || part == ".." {
+			part = "_"
+		}
+		parts = append(parts, part)
 	}
+	return filepath.Join(parts...) + ".json"
+}
+
+// collapses concurrent calls with the same key into a single call whose result is shared
+type flightGroup[T any] struct {
+	mutex sync.Mutex
+	calls map[string]*flightCall[T]
+}
+
+type flightCall[T any] struct {
+	done   chan struct{}
+	result T
+	err    error
+	// number of calls waiting for the result
+	dups int
+}
 
-	for _, oldTime := range times[1:] {
-		os.Remove(cache.getPath(oldTime))
+func (group *flightGroup[T]) do(key string, fn func() (T, error)) (result T, err error) {
+	group.mutex.Lock()
+	if group.calls == nil {
+		group.calls = make(map[string]*flightCall[T])
 	}
+	if call, ok := group.calls[key]; ok {
+		call.dups++
+		group.mutex.Unlock()
+		<-call.done
+		return call.result, call.err
+	}
+	call := &flightCall[T]{done: make(chan struct{})}
+	group.calls[key] = call
+	group.mutex.Unlock()
+
+	func() {
+		// waiting calls must be released even if fn panics
+		defer func() {
+			if r := recover(); r != nil {
+				call.err = fmt.Errorf("error: call %s panicked: %v", key, r)
+			}
+			close(call.done)
+
+			group.mutex.Lock()
+			delete(group.calls, key)
+			group.mutex.Unlock()
+		}()
+		call.result, call.err = fn()
+	}()
+
+	return call.result, call.err
 }
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFlightGroupPanic(t *testing.T) {
+	var group flightGroup[int]
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	var leaderErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, leaderErr = group.do("key", func() (int, error) {
+			close(started)
+			<-release
+			panic("fetch failed")
+		})
+	}()
+	<-started
+
+	waiterDone := make(chan error)
+	go func() {
+		_, err := group.do("key", func() (int, error) {
+			t.Error("waiting call ran its own function instead of joining")
+			return 1, nil
+		})
+		waiterDone <- err
+	}()
+	// release the leader only once the waiter joined the running call
+	for deadline := time.Now().Add(time.Second); getFlightDups(&group, "key") == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("waiting call did not join the running call")
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	if leaderErr == nil {
+		t.Error("expected the panic to be returned as error")
+	}
+	select {
+	case err := <-waiterDone:
+		if err == nil {
+			t.Error("expected the panic to be returned to the waiting call")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiting call is blocked after a panic")
+	}
+
+	result, err := group.do("key", func() (int, error) { return 2, nil })
+	if err != nil || result != 2 {
+		t.Errorf("got %d, %v after a panic, want 2, nil", result, err)
+	}
+}
+
+func getFlightDups(group *flightGroup[int], key string) int {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+
+	if call, ok := group.calls[key]; ok {
+		return call.dups
+	}
+	return 0
+}
+
+func TestFileCacheCleanup(t *testing.T) {
+	store := &FileCache{Dir: t.TempDir()}
+	store.Write("personal/old", []byte("[]"))
+	store.Write("personal/new", []byte("[]"))
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(store.getPath("personal/old"), old, old)
+
+	if err := store.Cleanup(time.Now().Add(-24 * time.Hour)); err != nil {
+		t.Fatalf("cleanup failed: %v", err)
+	}
+	if _, _, err := store.Load("personal/old"); err == nil {
+		t.Error("expired entry was kept")
+	}
+	if _, _, err := store.Load("personal/new"); err != nil {
+		t.Error("recent entry was removed")
+	}
+}
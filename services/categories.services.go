@@ -0,0 +1,191 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mcg-dallgow/mcg-display/services/webuntis"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+// rule assigning a category to events, empty fields match every event
+type CategoryRule struct {
+	Source   string   `json:"source"`
+	Calendar string   `json:"calendar"`
+	Title    string   `json:"title"`
+	Notes    string   `json:"notes"`
+	Classes  []string `json:"classes"`
+	Category string   `json:"category"`
+
+	titleRegex *regexp.Regexp
+	notesRegex *regexp.Regexp
+	category   EventCategory
+}
+
+type CategoryRules struct {
+	Rules   []CategoryRule `json:"rules"`
+	Default string         `json:"default"`
+
+	category EventCategory
+}
+
+// properties of an event that rules can match on
+type categoryInput struct {
+	Source   EventSource
+	Calendar string
+	Title    string
+	Notes    string
+	Classes  []string
+}
+
+// rules used if there is no config/categories.json
+var defaultCategoryRules = CategoryRules{
+	Rules: []CategoryRule{
+		{Source: string(ExamSource), Category: ExamEvent.Key()},
+		{Source: string(TimetableSource), Category: StudentEvent.Key()},
+		{Calendar: "Termine Jahrgang 7-9", Category: SekIEvent.Key()},
+		{Calendar: "Termine Jahrgang 10 und Oberstufe", Category: SekIIEvent.Key()},
+		{Calendar: "Lernende", Category: StudentEvent.Key()},
+		{Calendar: "Lehrkräfte", Category: TeacherEvent.Key()},
+		{Calendar: "Öffentlich", Title: "AG", Category: AGEvent.Key()},
+	},
+	Default: PublicEvent.Key(),
+}
+
+var categoryRules CategoryRules
+var categoryRulesModified time.Time
+var categoryRulesMutex sync.Mutex
+
+// load the category rules at start-up
+func InitCategoryRules() {
+	categoryRulesMutex.Lock()
+	defer categoryRulesMutex.Unlock()
+
+	categoryRules = defaultCategoryRules
+	categoryRules.compile()
+	reloadCategoryRules()
+}
+
+// get the current category rules, reloading them if the rules file was modified
+func getCategoryRules() CategoryRules {
+	categoryRulesMutex.Lock()
+	defer categoryRulesMutex.Unlock()
+
+	if categoryRules.Rules == nil {
+		categoryRules = defaultCategoryRules
+		categoryRules.compile()
+	}
+	reloadCategoryRules()
+
+	return categoryRules
+}
+
+func reloadCategoryRules() {
+	info, err := os.Stat(getConfigPath("categories"))
+	if err != nil || !info.ModTime().After(categoryRulesModified) {
+		return
+	}
+	categoryRulesModified = info.ModTime()
+
+	var rules CategoryRules
+	err = loadConfig("categories", &rules)
+	if err == nil {
+		err = rules.compile()
+	}
+	if err != nil {
+		// keep the previous rules if the new ones are invalid
+		log.Printf("loading category rules failed: %v", err)
+		return
+	}
+	categoryRules = rules
+}
+
+func (rules *CategoryRules) compile() (err error) {
+	rules.category, err = ParseEventCategory(rules.Default)
+	if err != nil {
+		return err
+	}
+	for i := range rules.Rules {
+		rule := &rules.Rules[i]
+		if rule.category, err = ParseEventCategory(rule.Category); err != nil {
+			return err
+		}
+		if rule.Title != "" {
+			if rule.titleRegex, err = regexp.Compile(rule.Title); err != nil {
+				return errors.New("error: invalid title pattern " + rule.Title)
+			}
+		}
+		if rule.Notes != "" {
+			if rule.notesRegex, err = regexp.Compile(rule.Notes); err != nil {
+				return errors.New("error: invalid notes pattern " + rule.Notes)
+			}
+		}
+	}
+	return nil
+}
+
+// get the category of the first matching rule
+func (rules *CategoryRules) classify(input categoryInput) EventCategory {
+	for _, rule := range rules.Rules {
+		if rule.matches(input) {
+			return rule.category
+		}
+	}
+	return rules.category
+}
+
+func (rule *CategoryRule) matches(input categoryInput) bool {
+	if rule.Source != "" && rule.Source != string(input.Source) {
+		return false
+	}
+	if rule.Calendar != "" && rule.Calendar != input.Calendar {
+		return false
+	}
+	if rule.titleRegex != nil && !rule.titleRegex.MatchString(input.Title) {
+		return false
+	}
+	if rule.notesRegex != nil && !rule.notesRegex.MatchString(input.Notes) {
+		return false
+	}
+	if len(rule.Classes) > 0 && !slices.ContainsFunc(input.Classes, func(class string) bool {
+		return slices.Contains(rule.Classes, class)
+	}) {
+		return false
+	}
+	return true
+}
+
+func getCalendarEventCategory(rules CategoryRules, event webuntis.CalendarEvent) EventCategory {
+	return rules.classify(categoryInput{
+		Source:   CalendarSource,
+		Calendar: event.Calendar,
+		Title:    event.Name,
+		Notes:    event.Notes,
+	})
+}
+
+func getTimetableEventCategory(rules CategoryRules, event webuntis.TimetableEvent) EventCategory {
+	return rules.classify(categoryInput{
+		Source:  TimetableSource,
+		Title:   event.Title,
+		Classes: event.Classes,
+	})
+}
+
+func getExamCategory(rules CategoryRules, exam webuntis.Exam) EventCategory {
+	var classes []string
+	for _, class := range exam.Classes {
+		classes = append(classes, class.DisplayName)
+	}
+	return rules.classify(categoryInput{
+		Source:  ExamSource,
+		Title:   exam.Name,
+		Notes:   exam.Text,
+		Classes: classes,
+	})
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/joho/godotenv"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+const defaultHistoryDir string = "./tmp/history/"
+const defaultHistoryDays int = 30
+const changeLogKey string = "changes"
+
+// events changed within this duration are marked on the display
+const recentChangeDuration time.Duration = 48 * time.Hour
+
+var history CacheStore = &FileCache{Dir: defaultHistoryDir}
+var historyDays = defaultHistoryDays
+var historyMutex sync.Mutex
+
+// configure where and how long the change log is kept from .env
+func InitHistory() {
+	godotenv.Load()
+
+	// the change log is kept in memory as well if nothing is to be written to disk
+	if os.Getenv("CACHE_BACKEND") == "memory" {
+		history = NewMemoryCache()
+	} else if dir := os.Getenv("HISTORY_DIR"); dir != "" {
+		history = &FileCache{Dir: dir}
+	}
+	if days, err := strconv.Atoi(os.Getenv("HISTORY_DAYS")); err == nil && days > 0 {
+		historyDays = days
+	}
+}
+
+// get all changes since the given time the filter allows, the most recent change being first
+func GetChanges(since time.Time, filter EventFilter) (changes []Change, err error) {
+	historyMutex.Lock()
+	allChanges, err := loadChanges()
+	historyMutex.Unlock()
+	if err != nil {
+		return changes, err
+	}
+
+	changes = []Change{}
+	for _, change := range allChanges {
+		// a change is hidden if the event was hidden before or after it
+		if change.Time.After(since) && (change.Before == nil || filter.Allows(*change.Before)) && (change.After == nil || filter.Allows(*change.After)) {
+			changes = append(changes, change)
+		}
+	}
+	slices.Reverse(changes)
+
+	return changes, nil
+}
+
+// compare freshly fetched events with the previous snapshot of the same days and log the differences
+func trackChanges(source, prefix string, start, end time.Time, events []Event) (changes []Change) {
+	previousEvents, knownDates := loadSnapshots(source, prefix, start, end)
+	// without a previous snapshot every event would be reported as new
+	if len(knownDates) == 0 {
+		return changes
+	}
+
+	changes = diffEvents(source, previousEvents, events, knownDates)
+	if len(changes) > 0 {
+		saveChanges(changes)
+		queueNotification(source, changes)
+	}
+
+	return changes
+}
+
+// keep the time unchanged events were last modified without logging changes, e.g. for personal views
+func keepModified(source, prefix string, start, end time.Time, events []Event) {
+	previousEvents, _ := loadSnapshots(source, prefix, start, end)
+	for i, event := range events {
+		for _, before := range previousEvents {
+			if before.Date == event.Date && isSameEvent(event, before) && !before.Modified.IsZero() && len(getChangedFields(before, event)) == 0 {
+				events[i].Modified = before.Modified
+				break
+			}
+		}
+	}
+}
+
+func loadSnapshots(source, prefix string, start, end time.Time) (previousEvents []Event, knownDates map[string]bool) {
+	knownDates = make(map[string]bool)
+	for day := start; !day.After(end); day = day.Add(24 * time.Hour) {
+		var dayEvents []Event
+		if loadSnapshot(source, getDayCacheKey(prefix, day), &dayEvents) == nil {
+			previousEvents = append(previousEvents, dayEvents...)
+			knownDates[day.Format("2006-01-02")] = true
+		}
+	}
+	return previousEvents, knownDates
+}
+
+func diffEvents(source string, previousEvents, events []Event, knownDates map[string]bool) (changes []Change) {
+	now := time.Now()
+	matched := make([]bool, len(events))
+
+	for _, before := range previousEvents {
+		// prefer an event on the same day if an event appears multiple times
+		index := -1
+		for i, event := range events {
+			if matched[i] || !isSameEvent(event, before) {
+				continue
+			}
+			if index == -1 || event.Date == before.Date {
+				index = i
+			}
+			if event.Date == before.Date {
+				break
+			}
+		}
+
+		if index == -1 {
+			changes = append(changes, Change{
+				Type:   EventRemoved,
+				Time:   now,
+				Source: source,
+				Before: &before,
+			})
+			continue
+		}
+
+		matched[index] = true
+		fields := getChangedFields(before, events[index])
+		// unchanged events keep the time they were last modified
+		if len(fields) == 0 && !before.Modified.IsZero() {
+			events[index].Modified = before.Modified
+		}
+		after := events[index]
+		if len(fields) > 0 {
+			changes = append(changes, Change{
+				Type:   EventChanged,
+				Time:   now,
+				Source: source,
+				Fields: fields,
+				Before: &before,
+				After:  &after,
+			})
+		}
+	}
+
+	for i, event := range events {
+		// events on days without snapshot are unknown rather than new
+		if matched[i] || !knownDates[event.Date] {
+			continue
+		}
+		changes = append(changes, Change{
+			Type:   EventAdded,
+			Time:   now,
+			Source: source,
+			After:  &event,
+		})
+	}
+
+	return changes
+}
+
+func getChangedFields(before, after Event) (fields []string) {
+	if before.Title != after.Title {
+		fields = append(fields, "Title")
+	}
+	if before.Description != after.Description {
+		fields = append(fields, "Description")
+	}
+	if before.Category != after.Category {
+		fields = append(fields, "Category")
+	}
+	if before.Date != after.Date {
+		fields = append(fields, "Date")
+	}
+	if before.FullDay != after.FullDay {
+		fields = append(fields, "FullDay")
+	}
+	if !before.Start.Equal(after.Start) {
+		fields = append(fields, "Start")
+	}
+	if !before.End.Equal(after.End) {
+		fields = append(fields, "End")
+	}
+	if before.Location != after.Location {
+		fields = append(fields, "Location")
+	}
+	// events cached before they had a status are scheduled
+	if before.Status != "" && before.Status != after.Status {
+		fields = append(fields, "Status")
+	}
+	return fields
+}
+
+// check if two events are the same event across fetches
+func isSameEvent(a, b Event) bool {
+	if a.Id != "" && b.Id != "" {
+		return a.Id == b.Id
+	}
+	// events cached before they had an id are identified by category and title
+	return getEventKey(a) == getEventKey(b)
+}
+
+func getEventKey(event Event) string {
+	return strconv.Itoa(int(event.Category)) + "-" + event.Title
+}
+
+// mark events that were added or changed recently
+func markRecentChanges(events []Event) {
+	changes, err := GetChanges(time.Now().Add(-recentChangeDuration), EventFilter{})
+	if err != nil {
+		return
+	}
+	// changes are ordered from new to old, so the most recent change is applied last
+	slices.Reverse(changes)
+
+	for i, event := range events {
+		for _, change := range changes {
+			if change.After != nil && change.After.Date == event.Date && isSameEvent(*change.After, event) {
+				events[i].Change = change.Type
+			}
+		}
+	}
+}
+
+func loadChanges() (changes []Change, err error) {
+	data, _, err := history.Load(changeLogKey)
+	if err != nil {
+		// there is no change log yet
+		return []Change{}, nil
+	}
+	err = json.Unmarshal(data, &changes)
+	return changes, err
+}
+
+func saveChanges(newChanges []Change) (err error) {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	changes, err := loadChanges()
+	if err != nil {
+		// start a new change log if the old one is corrupted
+		changes = []Change{}
+	}
+	changes = append(changes, newChanges...)
+
+	// only keep changes within the configured history
+	cutoff := time.Now().Add(-time.Duration(historyDays) * 24 * time.Hour)
+	changes = slices.DeleteFunc(changes, func(change Change) bool {
+		return change.Time.Before(cutoff)
+	})
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	return history.Write(changeLogKey, data)
+}
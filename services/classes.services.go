@@ -0,0 +1,173 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+// matches WebUntis class names like "7a", "10b", "Jhg 11" or "12/2"
+var classRegex = regexp.MustCompile(`^(?i)(Jhg|Jg|J)?\s*([0-9]{1,2})\s*([a-z]*|/[0-9]+)$`)
+
+func ParseClass(name string) (class Class, err error) {
+	class.Name = name
+	match := classRegex.FindStringSubmatch(strings.TrimSpace(name))
+	if match == nil {
+		return class, errors.New("error: invalid class name " + name)
+	}
+	class.Grade, _ = strconv.Atoi(match[2])
+	class.Suffix = strings.ToLower(match[3])
+	// grade levels are either named as such or have no suffix at all
+	class.GradeLevel = match[1] != "" || match[3] == ""
+	return class, nil
+}
+
+// parse class names, keeping names that cannot be parsed as they are
+func ParseClasses(names []string) (classes []Class) {
+	for _, name := range names {
+		class, _ := ParseClass(name)
+		classes = append(classes, class)
+	}
+	return classes
+}
+
+// format classes as a compact label, e.g. "7a–c", "Jg 7–9" or "alle Sek I"
+func FormatClasses(classes []Class) string {
+	var parts []string
+	var unknown []string
+	grades := make(map[int][]Class)
+	for _, class := range classes {
+		if class.Grade == 0 {
+			unknown = append(unknown, class.Name)
+		} else if !slices.ContainsFunc(grades[class.Grade], func(other Class) bool { return other.String() == class.String() }) {
+			grades[class.Grade] = append(grades[class.Grade], class)
+		}
+	}
+
+	gradeNumbers := []int{}
+	count := 0
+	for grade := range grades {
+		gradeNumbers = append(gradeNumbers, grade)
+		count += len(grades[grade])
+	}
+	slices.Sort(gradeNumbers)
+
+	// events for many classes across several grades concern whole grade levels
+	wholeGrades := []int{}
+	for _, grade := range gradeNumbers {
+		isGradeLevel := slices.ContainsFunc(grades[grade], func(class Class) bool { return class.GradeLevel })
+		if isGradeLevel || (count > 2 && len(gradeNumbers) > 1) {
+			wholeGrades = append(wholeGrades, grade)
+		}
+	}
+	if len(wholeGrades) > 0 {
+		parts = append(parts, formatGradeLevels(wholeGrades))
+	}
+
+	for _, grade := range gradeNumbers {
+		if !slices.Contains(wholeGrades, grade) {
+			parts = append(parts, formatGradeClasses(grades[grade]))
+		}
+	}
+
+	slices.Sort(unknown)
+	parts = append(parts, slices.Compact(unknown)...)
+	return strings.Join(parts, ", ")
+}
+
+// format whole grade levels, e.g. "Jg 7–9, 11" or "alle Sek I"
+func formatGradeLevels(grades []int) string {
+	var parts []string
+	allSekI := containsGradeRange(grades, FirstGrade, LastSekIGrade)
+	allSekII := containsGradeRange(grades, LastSekIGrade+1, LastGrade)
+
+	if allSekI && allSekII {
+		return "alle Jahrgänge"
+	}
+	if allSekI {
+		parts = append(parts, "alle Sek I")
+		grades = slices.DeleteFunc(slices.Clone(grades), func(grade int) bool { return grade <= LastSekIGrade })
+	}
+	if allSekII {
+		parts = append(parts, "alle Sek II")
+		grades = slices.DeleteFunc(slices.Clone(grades), func(grade int) bool { return grade > LastSekIGrade })
+	}
+
+	var ranges []string
+	for _, gradeRange := range getNumberRanges(grades) {
+		if gradeRange[0] == gradeRange[1] {
+			ranges = append(ranges, strconv.Itoa(gradeRange[0]))
+		} else {
+			ranges = append(ranges, strconv.Itoa(gradeRange[0])+"–"+strconv.Itoa(gradeRange[1]))
+		}
+	}
+	if len(ranges) > 0 {
+		parts = append(parts, "Jg "+strings.Join(ranges, ", "))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// format classes of the same grade, e.g. "7a–c" or "7a, 7c"
+func formatGradeClasses(classes []Class) string {
+	slices.SortFunc(classes, func(a, b Class) int {
+		return strings.Compare(a.Suffix, b.Suffix)
+	})
+
+	// only single letter suffixes can be combined into ranges
+	letters := []int{}
+	for _, class := range classes {
+		if len(class.Suffix) != 1 || class.Suffix[0] < 'a' || class.Suffix[0] > 'z' {
+			letters = nil
+			break
+		}
+		letters = append(letters, int(class.Suffix[0]))
+	}
+	if len(letters) < 3 {
+		names := []string{}
+		for _, class := range classes {
+			names = append(names, class.String())
+		}
+		return strings.Join(names, ", ")
+	}
+
+	grade := strconv.Itoa(classes[0].Grade)
+	var ranges []string
+	for _, letterRange := range getNumberRanges(letters) {
+		first, last := string(rune(letterRange[0])), string(rune(letterRange[1]))
+		switch letterRange[1] - letterRange[0] {
+		case 0:
+			ranges = append(ranges, first)
+		case 1:
+			ranges = append(ranges, first, last)
+		default:
+			ranges = append(ranges, first+"–"+last)
+		}
+	}
+	return grade + strings.Join(ranges, ", ")
+}
+
+func containsGradeRange(grades []int, first, last int) bool {
+	for grade := first; grade <= last; grade++ {
+		if !slices.Contains(grades, grade) {
+			return false
+		}
+	}
+	return true
+}
+
+// group sorted numbers into ranges of consecutive numbers
+func getNumberRanges(numbers []int) (numberRanges [][2]int) {
+	for _, number := range numbers {
+		if len(numberRanges) > 0 && numberRanges[len(numberRanges)-1][1]+1 == number {
+			numberRanges[len(numberRanges)-1][1] = number
+		} else {
+			numberRanges = append(numberRanges, [2]int{number, number})
+		}
+	}
+	return numberRanges
+}
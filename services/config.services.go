@@ -0,0 +1,26 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+const defaultConfigDir string = "./config/"
+
+// load a JSON configuration file from the config directory into value
+func loadConfig(name string, value any) (err error) {
+	data, err := os.ReadFile(getConfigPath(name))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+func getConfigPath(name string) string {
+	dir := os.Getenv("CONFIG_DIR")
+	if dir == "" {
+		dir = defaultConfigDir
+	}
+	return filepath.Join(dir, name+".json")
+}
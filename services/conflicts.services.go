@@ -0,0 +1,158 @@
+package services
+
+import (
+	"slices"
+	"time"
+
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+// get conflicts between the exams, calendar and timetable events within the date range the filter allows
+func GetConflicts(start, end time.Time, filter EventFilter) (conflicts []Conflict, err error) {
+	end = limitDateRange(start, end)
+	events, err := GetEvents(start, end, nil, filter)
+	if err != nil {
+		return conflicts, err
+	}
+
+	eventList := []Event{}
+	for day := start; !day.After(end); day = day.Add(24 * time.Hour) {
+		eventList = append(eventList, events[day.Format("2006-01-02")]...)
+	}
+	return findConflicts(eventList), nil
+}
+
+func findConflicts(events []Event) (conflicts []Conflict) {
+	conflicts = []Conflict{}
+	for i, a := range events {
+		for _, b := range events[i+1:] {
+			if !areOverlapping(a, b) {
+				continue
+			}
+			if classes := getCommonGroups(a, b); len(classes) > 0 {
+				conflicts = append(conflicts, newConflict(ClassConflict, classes, a, b))
+			}
+			// exams written at the same time in one room, e.g. in the hall, are planned together
+			if !(slices.Contains(a.Sources, ExamSource) && slices.Contains(b.Sources, ExamSource) && a.Start.Equal(b.Start) && a.End.Equal(b.End)) {
+				if rooms := getCommonItems(a.Rooms, b.Rooms); len(rooms) > 0 {
+					conflicts = append(conflicts, newConflict(RoomConflict, rooms, a, b))
+				}
+			}
+			if teachers := getCommonItems(a.Teachers, b.Teachers); len(teachers) > 0 {
+				conflicts = append(conflicts, newConflict(TeacherConflict, teachers, a, b))
+			}
+		}
+	}
+	return conflicts
+}
+
+// mark every event with the kinds of conflicts it is part of
+func markConflicts(events []Event) {
+	for _, conflict := range findConflicts(events) {
+		for i, event := range events {
+			if event.Date != conflict.Date || !slices.ContainsFunc(conflict.Events, func(other Event) bool {
+				return isSameEvent(other, event)
+			}) {
+				continue
+			}
+			if !slices.Contains(events[i].Conflicts, conflict.Type) {
+				events[i].Conflicts = append(events[i].Conflicts, conflict.Type)
+			}
+		}
+	}
+}
+
+func newConflict(conflictType ConflictType, resources []string, a, b Event) Conflict {
+	return Conflict{
+		Type:      conflictType,
+		Resources: resources,
+		Date:      a.Date,
+		Events:    []Event{a, b},
+	}
+}
+
+func areOverlapping(a, b Event) bool {
+	if a.Date != b.Date || (a.Id != "" && a.Id == b.Id) {
+		return false
+	}
+	if a.Status == EventCancelled || b.Status == EventCancelled {
+		return false
+	}
+	if a.FullDay || b.FullDay {
+		return true
+	}
+	return a.Start.Before(b.End) && b.Start.Before(a.End)
+}
+
+// get the classes or courses both events are held for, Sek II exams only share their course
+func getCommonGroups(a, b Event) (common []string) {
+	if a.Course != "" && b.Course != "" && isSekIIEvent(a) && isSekIIEvent(b) {
+		if Normalize(a.Course) == Normalize(b.Course) {
+			return []string{a.Course}
+		}
+		return common
+	}
+	// calendar events like class trips only name their classes in the title
+	return getCommonClasses(getMentionedClasses(a), getMentionedClasses(b))
+}
+
+// get the classes an event is held for
+func getEventClasses(event Event) (classes []string) {
+	for _, class := range event.Classes {
+		classes = append(classes, Normalize(class))
+	}
+	return classes
+}
+
+// get the classes of an event, calendar events only mention them in their title
+func getMentionedClasses(event Event) (classes []string) {
+	if len(event.Classes) > 0 {
+		return getEventClasses(event)
+	}
+	_, classes = getTitleTokens(event.Title)
+	return classes
+}
+
+func isSekIIEvent(event Event) bool {
+	return slices.ContainsFunc(ParseClasses(event.Classes), func(class Class) bool {
+		return class.Level() == SekII
+	})
+}
+
+// get the classes contained in both lists, a grade level contains all of its classes
+func getCommonClasses(classesA, classesB []string) (common []string) {
+	for _, classA := range classesA {
+		for _, classB := range classesB {
+			matchA := classTokenRegex.FindStringSubmatch(classA)
+			matchB := classTokenRegex.FindStringSubmatch(classB)
+			if matchA == nil || matchB == nil {
+				if classA == classB && !slices.Contains(common, classA) {
+					common = append(common, classA)
+				}
+				continue
+			}
+			if matchA[1] != matchB[1] || (matchA[2] != "" && matchB[2] != "" && matchA[2] != matchB[2]) {
+				continue
+			}
+			// the more specific class is reported
+			class := classA
+			if matchA[2] == "" {
+				class = classB
+			}
+			if !slices.Contains(common, class) {
+				common = append(common, class)
+			}
+		}
+	}
+	return common
+}
+
+func getCommonItems(a, b []string) (common []string) {
+	for _, item := range a {
+		// empty names, e.g. of exams without room, are not shared
+		if item != "" && slices.Contains(b, item) && !slices.Contains(common, item) {
+			common = append(common, item)
+		}
+	}
+	return common
+}
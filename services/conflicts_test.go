@@ -0,0 +1,38 @@
+package services
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+func TestFindConflictsWithClassTrip(t *testing.T) {
+	start := time.Date(2024, 5, 6, 8, 0, 0, 0, time.UTC)
+	exam := Event{Id: "exams-1", Title: "KA 7a Mathe", Category: ExamEvent, Date: "2024-05-06", Start: start, End: start.Add(90 * time.Minute), Sources: []EventSource{ExamSource}, Classes: []string{"7a"}}
+	trip := Event{Id: "calendar-1", Title: "Klassenfahrt 7a", Category: SekIEvent, Date: "2024-05-06", FullDay: true, Start: start, End: start.Add(24 * time.Hour), Sources: []EventSource{CalendarSource}}
+	other := Event{Id: "calendar-2", Title: "Klassenfahrt 8b", Category: SekIEvent, Date: "2024-05-06", FullDay: true, Start: start, End: start.Add(24 * time.Hour), Sources: []EventSource{CalendarSource}}
+
+	conflicts := findConflicts([]Event{exam, trip, other})
+	if !slices.ContainsFunc(conflicts, func(conflict Conflict) bool {
+		return conflict.Type == ClassConflict && slices.Equal(conflict.Resources, []string{"7a"}) && conflict.Events[1].Id == trip.Id
+	}) {
+		t.Errorf("exam and class trip of 7a do not conflict: %+v", conflicts)
+	}
+	if slices.ContainsFunc(conflicts, func(conflict Conflict) bool {
+		return slices.ContainsFunc(conflict.Events, func(event Event) bool { return event.Id == other.Id })
+	}) {
+		t.Errorf("class trip of 8b conflicts: %+v", conflicts)
+	}
+}
+
+func TestFindConflictsWithSekIICourses(t *testing.T) {
+	start := time.Date(2024, 5, 6, 8, 0, 0, 0, time.UTC)
+	math := Event{Id: "exams-1", Title: "Klausur Mathe LK", Category: ExamEvent, Date: "2024-05-06", Start: start, End: start.Add(3 * time.Hour), Sources: []EventSource{ExamSource}, Classes: []string{"Jhg 11"}, Course: "ma-lk1"}
+	english := Event{Id: "exams-2", Title: "Klausur Englisch GK", Category: ExamEvent, Date: "2024-05-06", Start: start, End: start.Add(3 * time.Hour), Sources: []EventSource{ExamSource}, Classes: []string{"Jhg 11"}, Course: "en-gk2"}
+
+	if conflicts := findConflicts([]Event{math, english}); len(conflicts) > 0 {
+		t.Errorf("exams of different Sek II courses conflict: %+v", conflicts)
+	}
+}
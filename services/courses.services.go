@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+var courseLevels = []CourseLevel{BasicCourse, AdvancedCourse, SeminarCourse}
+
+// parse a WebUntis course code into its parts, e.g.
+//
+//	"MA-LK1"     Mathematik, LK, 1
+//	"en-gk2"     Englisch, GK, 2
+//	"SKde"       Deutsch, Seminarkurs
+//	"deSK1"      Deutsch, Seminarkurs, 1
+//	"ge-gk1-Mül" Geschichte, GK, 1, Mül
+//	"PH"         Physik
+func ParseCourse(code string) (course Course, err error) {
+	course.Code = code
+
+	// teachers may be given in parentheses, e.g. "bi-lk1 (Sch)"
+	if open := strings.Index(code, "("); open != -1 {
+		course.Teacher = strings.Trim(code[open+1:], " )")
+		code = code[:open]
+	}
+
+	for _, token := range splitCourseCode(code) {
+		if number, err := strconv.Atoi(token); err == nil {
+			if course.Number == 0 {
+				course.Number = number
+			}
+			continue
+		}
+
+		subject, level, ok := decomposeCourseToken(token)
+		if !ok {
+			// unknown words after the subject are the teacher's abbreviation
+			if !course.Subject.IsEmpty() && course.Teacher == "" {
+				course.Teacher = token
+			}
+			continue
+		}
+		if course.Subject.IsEmpty() {
+			course.Subject = subject
+		}
+		if course.Level == NoCourseLevel {
+			course.Level = level
+		}
+	}
+
+	// seminar courses without a subject of their own belong to the seminar subject
+	if course.Subject.IsEmpty() && course.Level == SeminarCourse {
+		course.Subject, _ = getSubjectByCode(string(SeminarCourse))
+	}
+	if course.Subject.IsEmpty() {
+		return course, errors.New("error: unknown course code " + course.Code)
+	}
+	return course, nil
+}
+
+// split a course code into runs of letters and digits
+func splitCourseCode(code string) (tokens []string) {
+	var current []rune
+	for _, r := range code {
+		if len(current) > 0 && (unicode.IsDigit(r) != unicode.IsDigit(current[0]) || !unicode.IsLetter(r) && !unicode.IsDigit(r)) {
+			tokens = append(tokens, string(current))
+			current = nil
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			current = append(current, r)
+		}
+	}
+	if len(current) > 0 {
+		tokens = append(tokens, string(current))
+	}
+	return tokens
+}
+
+// decompose a run of letters like "SKde" into course levels and subject codes
+func decomposeCourseToken(token string) (subject Subject, level CourseLevel, ok bool) {
+	rest := strings.ToUpper(token)
+	for rest != "" {
+		matched := false
+		for _, courseLevel := range courseLevels {
+			if strings.HasPrefix(rest, string(courseLevel)) {
+				level = courseLevel
+				rest = rest[len(courseLevel):]
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		code := ""
+		for _, currSubject := range subjects {
+			for _, currCode := range currSubject.Codes {
+				if len(currCode) > len(code) && strings.HasPrefix(rest, strings.ToUpper(currCode)) {
+					subject = currSubject
+					code = currCode
+				}
+			}
+		}
+		if code == "" {
+			return Subject{}, NoCourseLevel, false
+		}
+		rest = rest[len(code):]
+	}
+	return subject, level, true
+}
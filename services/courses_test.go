@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+func TestParseCourse(t *testing.T) {
+	tests := []struct {
+		code    string
+		subject string
+		level   CourseLevel
+		number  int
+		teacher string
+		err     bool
+	}{
+		{code: "MA-LK1", subject: "Mathematik", level: AdvancedCourse, number: 1},
+		{code: "en-gk2", subject: "Englisch", level: BasicCourse, number: 2},
+		{code: "SKde", subject: "Deutsch", level: SeminarCourse},
+		{code: "deSK1", subject: "Deutsch", level: SeminarCourse, number: 1},
+		{code: "SK2", subject: "Seminarkurs", level: SeminarCourse, number: 2},
+		{code: "Ma-Lk3", subject: "Mathematik", level: AdvancedCourse, number: 3},
+		{code: "bi-lk", subject: "Biologie", level: AdvancedCourse},
+		{code: "PH", subject: "Physik"},
+		{code: "ge-gk1-Mül", subject: "Geschichte", level: BasicCourse, number: 1, teacher: "Mül"},
+		{code: "bi-lk1 (Sch)", subject: "Biologie", level: AdvancedCourse, number: 1, teacher: "Sch"},
+		{code: "xyz-7", level: NoCourseLevel, number: 7, err: true},
+		{code: "", err: true},
+	}
+
+	for _, test := range tests {
+		course, err := ParseCourse(test.code)
+		if (err != nil) != test.err {
+			t.Errorf("ParseCourse(%q) returned error %v", test.code, err)
+		}
+		if course.Code != test.code {
+			t.Errorf("ParseCourse(%q).Code = %q", test.code, course.Code)
+		}
+		if course.Subject.Name != test.subject {
+			t.Errorf("ParseCourse(%q).Subject = %q, want %q", test.code, course.Subject.Name, test.subject)
+		}
+		if course.Level != test.level {
+			t.Errorf("ParseCourse(%q).Level = %q, want %q", test.code, course.Level, test.level)
+		}
+		if course.Number != test.number {
+			t.Errorf("ParseCourse(%q).Number = %d, want %d", test.code, course.Number, test.number)
+		}
+		if course.Teacher != test.teacher {
+			t.Errorf("ParseCourse(%q).Teacher = %q, want %q", test.code, course.Teacher, test.teacher)
+		}
+	}
+}
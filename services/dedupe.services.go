@@ -0,0 +1,163 @@
+package services
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+// sources in order of precedence when merging equivalent events
+var sourcePrecedence = []EventSource{CalendarSource, ExamSource, TimetableSource}
+
+// words ignored when comparing titles
+var titleStopWords = []string{"der", "die", "das", "den", "dem", "und", "fuer", "im", "in", "am", "an", "zum", "zur", "jg"}
+
+var classTokenRegex = regexp.MustCompile("^([0-9]{1,2})([a-z]?)$")
+
+// merge events that appear in multiple sources, e.g. both in the calendar and the timetable
+func dedupeEvents(events []Event) (deduped []Event) {
+	deduped = []Event{}
+	for _, event := range events {
+		index := slices.IndexFunc(deduped, func(other Event) bool {
+			return areEquivalent(other, event)
+		})
+		if index == -1 {
+			deduped = append(deduped, event)
+		} else {
+			deduped[index] = mergeEvents(deduped[index], event)
+		}
+	}
+	return deduped
+}
+
+func areEquivalent(a, b Event) bool {
+	if a.Date != b.Date {
+		return false
+	}
+	// events of the same source are never duplicates of each other
+	for _, source := range a.Sources {
+		if slices.Contains(b.Sources, source) {
+			return false
+		}
+	}
+	if !a.FullDay && !b.FullDay && !(a.Start.Before(b.End) && b.Start.Before(a.End)) && !a.Start.Equal(b.Start) {
+		return false
+	}
+
+	wordsA, classesA := getTitleTokens(a.Title)
+	wordsB, classesB := getTitleTokens(b.Title)
+	if !haveCommonClasses(classesA, classesB) {
+		return false
+	}
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return false
+	}
+
+	common := 0
+	for _, word := range wordsA {
+		if slices.Contains(wordsB, word) {
+			common++
+		}
+	}
+	return float32(common)/float32(min(len(wordsA), len(wordsB))) >= 0.6
+}
+
+// split a title into normalized words and class names
+func getTitleTokens(title string) (words, classes []string) {
+	tokens := strings.FieldsFunc(Normalize(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, token := range tokens {
+		if classTokenRegex.MatchString(token) {
+			classes = append(classes, token)
+		} else if !slices.Contains(titleStopWords, token) && !slices.Contains(words, token) {
+			words = append(words, token)
+		}
+	}
+	return words, classes
+}
+
+// check if two lists of classes or grade levels overlap, events without classes match every class
+func haveCommonClasses(classesA, classesB []string) bool {
+	if len(classesA) == 0 || len(classesB) == 0 {
+		return true
+	}
+	for _, classA := range classesA {
+		for _, classB := range classesB {
+			matchA := classTokenRegex.FindStringSubmatch(classA)
+			matchB := classTokenRegex.FindStringSubmatch(classB)
+			// a grade level matches all of its classes
+			if matchA[1] == matchB[1] && (matchA[2] == "" || matchB[2] == "" || matchA[2] == matchB[2]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func mergeEvents(a, b Event) (merged Event) {
+	primary, secondary := a, b
+	if getSourceRank(b) < getSourceRank(a) {
+		primary, secondary = b, a
+	}
+	merged = primary
+
+	if merged.Description == "" {
+		merged.Description = secondary.Description
+	} else if secondary.Description != "" && !Contains(merged.Description, secondary.Description, false) {
+		merged.Description += "\n" + secondary.Description
+	}
+	if merged.Location == "" {
+		merged.Location = secondary.Location
+	}
+	// exact times are preferred over full day entries
+	if merged.FullDay && !secondary.FullDay {
+		merged.FullDay = false
+		merged.Start = secondary.Start
+		merged.End = secondary.End
+	}
+
+	merged.Classes = mergeLists(merged.Classes, secondary.Classes)
+	merged.Teachers = mergeLists(merged.Teachers, secondary.Teachers)
+	merged.Rooms = mergeLists(merged.Rooms, secondary.Rooms)
+	if merged.Subject == "" {
+		merged.Subject = secondary.Subject
+		merged.Course = secondary.Course
+	}
+	if secondary.Modified.After(merged.Modified) {
+		merged.Modified = secondary.Modified
+	}
+
+	merged.Sources = []EventSource{}
+	for _, source := range sourcePrecedence {
+		if slices.Contains(a.Sources, source) || slices.Contains(b.Sources, source) {
+			merged.Sources = append(merged.Sources, source)
+		}
+	}
+
+	return merged
+}
+
+// append the items of b missing in a
+func mergeLists(a, b []string) (merged []string) {
+	merged = slices.Clone(a)
+	for _, item := range b {
+		if !slices.Contains(merged, item) {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
+
+func getSourceRank(event Event) int {
+	rank := len(sourcePrecedence)
+	for _, source := range event.Sources {
+		if index := slices.Index(sourcePrecedence, source); index != -1 && index < rank {
+			rank = index
+		}
+	}
+	return rank
+}
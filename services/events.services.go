@@ -1,10 +1,9 @@
 package services
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"regexp"
+	"hash/fnv"
 	"slices"
 	"strings"
 	"time"
@@ -13,30 +12,44 @@ import (
 	. "github.com/mcg-dallgow/mcg-display/types"
 )
 
-func GetEvents(start, end time.Time, person string, personType webuntis.PersonType) (events map[string][]Event, err error) {
+// teacher, student or class whose events are shown
+type Resource struct {
+	Type webuntis.PersonType
+	Name string
+}
+
+func GetEvents(start, end time.Time, resources []Resource, filter EventFilter) (events map[string][]Event, err error) {
+	return getEvents(start, end, resources, filter, false)
+}
+
+// fetch events from WebUntis regardless of the cache state and store them in the cache
+func PrefetchEvents(start, end time.Time, resources []Resource) (err error) {
+	_, err = getEvents(start, end, resources, EventFilter{}, true)
+	return err
+}
+
+var fetchGroup flightGroup[[]Event]
+var personsGroup flightGroup[[]webuntis.UntisValue]
+
+func getEvents(start, end time.Time, resources []Resource, filter EventFilter, refresh bool) (events map[string][]Event, err error) {
 	eventList := []Event{}
 
-	username, password, err := GetCredentials()
-	if err != nil {
-		return events, err
-	}
-	session, err := webuntis.LoginPassword(username, password)
-	if err != nil {
-		return events, err
-	}
-	defer session.Logout()
+	// cached data is served without logging in to WebUntis
+	session := &lazySession{}
+	defer session.logout()
+	updateTeacherDirectory(session)
 
-	exams, err := getExams(session, start, end)
+	exams, err := getExams(session, start, end, refresh)
 	if err != nil {
 		return events, err
 	}
 
-	if person == "" {
-		calendarEvents, err := getCalendarEvents(session, start, end)
+	if len(resources) == 0 {
+		calendarEvents, err := getCalendarEvents(session, start, end, refresh)
 		if err != nil {
 			return events, err
 		}
-		timetableEvents, err := getTimetableEvents(session, start, end)
+		timetableEvents, err := getTimetableEvents(session, start, end, refresh)
 		if err != nil {
 			return events, err
 		}
@@ -44,31 +57,31 @@ func GetEvents(start, end time.Time, person string, personType webuntis.PersonTy
 		eventList = append(eventList, calendarEvents...)
 		eventList = append(eventList, timetableEvents...)
 	} else {
-		individualEvents, err := getIndividualEvents(session, person, personType, start, end)
-		if err != nil {
-			return events, err
-		}
-		for _, exam := range exams {
-			if Contains(exam.Title, person[:4], false) {
-				eventList = append(eventList, exam)
+		for _, resource := range resources {
+			resourceEvents, err := getResourceEvents(session, exams, resource, start, end, refresh)
+			if err != nil {
+				return events, err
 			}
-		}
-		for _, individualEvent := range individualEvents {
-			if individualEvent.Category.String() == "AG" && personType == webuntis.TypeTeacher {
-				if Contains(individualEvent.Description, person[:4], false) {
-					eventList = append(eventList, individualEvent)
+			// events are only annotated with their resource if several resources are combined
+			if len(resources) > 1 {
+				for i := range resourceEvents {
+					resourceEvents[i].Resources = []string{resource.Name}
 				}
-			} else if individualEvent.Category.String() == "Prüfung" {
-				if personType == webuntis.TypeStudent {
-					eventList = append(eventList, individualEvent)
-				}
-			} else {
-				eventList = append(eventList, individualEvent)
 			}
+			eventList = append(eventList, resourceEvents...)
 		}
+		eventList = mergeResourceEvents(eventList)
 	}
 
+	eventList = dedupeEvents(eventList)
+	// conflicts are determined before filtering, as they may involve hidden events
+	if filter.Audience == StaffAudience {
+		markConflicts(eventList)
+	}
+	eventList = filterEvents(eventList, filter)
+	eventList = formatEventTeachers(eventList, filter.TeacherFormat)
 	sortEvents(eventList)
+	markRecentChanges(eventList)
 
 	events = make(map[string][]Event)
 	currentTime := start
@@ -87,390 +100,436 @@ func GetEvents(start, end time.Time, person string, personType webuntis.PersonTy
 	return events, nil
 }
 
-func getExams(session webuntis.Session, start, end time.Time) (events []Event, err error) {
-	cache := Cache{"exams", start, end}
-	events, err = getCachedEvents(cache)
-	if err == nil && len(events) > 0 {
-		return events, nil
-	}
-
-	exams, err := session.GetExams(start, end, false)
+// get the events of a single teacher, student or class
+func getResourceEvents(session *lazySession, exams []Event, resource Resource, start, end time.Time, refresh bool) (events []Event, err error) {
+	individualEvents, err := getIndividualEvents(session, resource.Name, resource.Type, start, end, refresh)
 	if err != nil {
 		return events, err
 	}
 
-	for _, exam := range exams {
-		events = append(events, Event{
-			Title:       generateExamTitle(exam),
-			Description: generateExamDescription(exam),
-			Category:    ExamEvent,
-			Date:        exam.Start.Format("2006-01-02"),
-			FullDay:     false,
-			Start:       exam.Start.Time,
-			End:         exam.End.Time,
-			Location:    formatLocation(exam.Rooms[0].ShortName),
-		})
+	if resource.Type == webuntis.TypeTeacher {
+		for _, exam := range exams {
+			if hasEventTeacher(exam, resource.Name) {
+				events = append(events, exam)
+			}
+		}
+	}
+	for _, individualEvent := range individualEvents {
+		if individualEvent.Category == AGEvent && resource.Type == webuntis.TypeTeacher {
+			if hasEventTeacher(individualEvent, resource.Name) {
+				events = append(events, individualEvent)
+			}
+		} else if individualEvent.Category == ExamEvent {
+			if resource.Type != webuntis.TypeTeacher {
+				events = append(events, individualEvent)
+			}
+		} else {
+			events = append(events, individualEvent)
+		}
 	}
-
-	eventsJson, err := json.Marshal(events)
-	cache.Write(eventsJson)
 
 	return events, nil
 }
 
-func getCalendarEvents(session webuntis.Session, start, end time.Time) (events []Event, err error) {
-	cache := Cache{"calendar", start, end}
-	events, err = getCachedEvents(cache)
-	if err == nil && len(events) > 0 {
-		return events, nil
-	}
-
-	calendarEvents, err := session.GetCalendarEvents(start, end)
-	if err != nil {
-		return events, err
-	}
-
-	for _, calendarEvent := range calendarEvents {
-		events = append(events, Event{
-			Title:       calendarEvent.Name,
-			Description: calendarEvent.Notes,
-			Category:    getCalendarEventCategory(calendarEvent),
-			Date:        calendarEvent.Date,
-			FullDay:     calendarEvent.FullDay,
-			Start:       calendarEvent.Start,
-			End:         calendarEvent.End,
-			Location:    formatLocation(calendarEvent.Location),
+// combine events appearing for several selected resources into one event
+func mergeResourceEvents(events []Event) (merged []Event) {
+	merged = []Event{}
+	for _, event := range events {
+		index := slices.IndexFunc(merged, func(other Event) bool {
+			return other.Date == event.Date && other.Start.Equal(event.Start) && isSameEvent(other, event)
 		})
+		if index == -1 {
+			merged = append(merged, event)
+			continue
+		}
+		for _, resource := range event.Resources {
+			if !slices.Contains(merged[index].Resources, resource) {
+				merged[index].Resources = append(merged[index].Resources, resource)
+			}
+		}
 	}
-
-	eventsJson, err := json.Marshal(events)
-	cache.Write(eventsJson)
-
-	return events, nil
+	return merged
 }
 
-func getTimetableEvents(session webuntis.Session, start, end time.Time) (events []Event, err error) {
-	cache := Cache{"timetable", start, end}
-	events, err = getCachedEvents(cache)
-	if err == nil && len(events) > 0 {
-		return events, nil
-	}
+func getExams(session *lazySession, start, end time.Time, refresh bool) (events []Event, err error) {
+	return getDailyEvents(examsCache, "", start, end, refresh, func(start, end time.Time) (events []Event, err error) {
+		untis, err := session.get()
+		if err != nil {
+			return events, err
+		}
+		exams, err := untis.GetExams(start, end, false)
+		if err != nil {
+			return events, err
+		}
+		for day := start; !day.After(end); day = day.Add(24 * time.Hour) {
+			dayExams := slices.DeleteFunc(slices.Clone(exams), func(exam webuntis.Exam) bool {
+				return exam.Start.Format("2006-01-02") != day.Format("2006-01-02")
+			})
+			writeCached(examsCache, getDayCacheKey(rawExamsPrefix, day), dayExams)
+		}
+		rules := getCategoryRules()
 
-	timetableEvents, err := session.GetTimetableEvents(start, end)
-	if err != nil {
-		return events, err
-	}
+		for _, exam := range exams {
+			events = append(events, newExamEvent(rules, exam))
+		}
 
-	for _, timetableEvent := range timetableEvents {
-		title := fmt.Sprintf("%s %s %s", timetableEvent.Title, getClassesOrGradeLevels(timetableEvent.Classes), getTeacher(timetableEvent.Teachers))
+		return events, nil
+	})
+}
 
-		events = append(events, Event{
-			Title:    title,
-			Category: StudentEvent,
-			Date:     timetableEvent.Start.Format("2006-01-02"),
-			FullDay:  false,
-			Start:    timetableEvent.Start,
-			End:      timetableEvent.End,
-		})
-	}
+func getCalendarEvents(session *lazySession, start, end time.Time, refresh bool) (events []Event, err error) {
+	return getDailyEvents(calendarCache, "", start, end, refresh, func(start, end time.Time) (events []Event, err error) {
+		untis, err := session.get()
+		if err != nil {
+			return events, err
+		}
+		calendarEvents, err := untis.GetCalendarEvents(start, end)
+		if err != nil {
+			return events, err
+		}
+		rules := getCategoryRules()
 
-	eventsJson, err := json.Marshal(events)
-	cache.Write(eventsJson)
+		for _, calendarEvent := range calendarEvents {
+			events = append(events, newCalendarEvent(rules, calendarEvent))
+		}
 
-	return events, err
+		return events, nil
+	})
 }
 
-func getIndividualEvents(session webuntis.Session, person string, personType webuntis.PersonType, start, end time.Time) (events []Event, err error) {
-	cache := Cache{string(personType) + person, start, end}
-	events, err = getCachedEvents(cache)
-	if err == nil && len(events) > 0 {
-		return events, nil
-	}
+func getTimetableEvents(session *lazySession, start, end time.Time, refresh bool) (events []Event, err error) {
+	return getDailyEvents(timetableCache, "", start, end, refresh, func(start, end time.Time) (events []Event, err error) {
+		untis, err := session.get()
+		if err != nil {
+			return events, err
+		}
+		timetableEvents, err := untis.GetTimetableEvents(start, end)
+		if err != nil {
+			return events, err
+		}
+		rules := getCategoryRules()
 
-	timetableEvents, calendarEvents, exams, err := session.GetIndividualEvents(person, personType, start, end)
-	if err != nil {
-		return events, err
-	}
+		for _, timetableEvent := range timetableEvents {
+			events = append(events, newTimetableEvent(rules, timetableEvent))
+		}
 
-	for _, timetableEvent := range timetableEvents {
-		title := fmt.Sprintf("%s %s %s", timetableEvent.Title, getClassesOrGradeLevels(timetableEvent.Classes), getTeacher(timetableEvent.Teachers))
+		return events, nil
+	})
+}
 
-		events = append(events, Event{
-			Title:    title,
-			Category: StudentEvent,
-			Date:     timetableEvent.Start.Format("2006-01-02"),
-			FullDay:  false,
-			Start:    timetableEvent.Start,
-			End:      timetableEvent.End,
-		})
-	}
+func getIndividualEvents(session *lazySession, person string, personType webuntis.PersonType, start, end time.Time, refresh bool) (events []Event, err error) {
+	prefix := string(personType) + "-" + person
+	return getDailyEvents(personalCache, prefix, start, end, refresh, func(start, end time.Time) (events []Event, err error) {
+		personData, err := getPerson(session, person, personType)
+		if err != nil {
+			return events, err
+		}
+		untis, err := session.get()
+		if err != nil {
+			return events, err
+		}
+		timetableEvents, calendarEvents, exams, err := untis.GetIndividualEvents(personData, personType, start, end)
+		if err != nil {
+			return events, err
+		}
+		rules := getCategoryRules()
 
-	for _, calendarEvent := range calendarEvents {
-		events = append(events, Event{
-			Title:       calendarEvent.Name,
-			Description: calendarEvent.Notes,
-			Category:    getCalendarEventCategory(calendarEvent),
-			Date:        calendarEvent.Date,
-			FullDay:     calendarEvent.FullDay,
-			Start:       calendarEvent.Start,
-			End:         calendarEvent.End,
-			Location:    formatLocation(calendarEvent.Location),
-		})
-	}
+		for _, timetableEvent := range timetableEvents {
+			events = append(events, newTimetableEvent(rules, timetableEvent))
+		}
 
-	for _, exam := range exams {
-		events = append(events, Event{
-			Title:       generateExamTitle(exam),
-			Description: generateExamDescription(exam),
-			Category:    ExamEvent,
-			Date:        exam.Start.Time.Format("2006-01-02"),
-			FullDay:     false,
-			Start:       exam.Start.Time,
-			End:         exam.End.Time,
-			Location:    exam.Rooms[0].ShortName,
-		})
-	}
+		for _, calendarEvent := range calendarEvents {
+			events = append(events, newCalendarEvent(rules, calendarEvent))
+		}
 
-	eventsJson, err := json.Marshal(events)
-	cache.Write(eventsJson)
+		for _, exam := range exams {
+			events = append(events, newExamEvent(rules, exam))
+		}
 
-	return events, nil
+		return events, nil
+	})
 }
 
-func getCachedEvents(cache Cache) (events []Event, err error) {
-	if !cache.IsValid() {
-		return events, errors.New("error: event cache is not valid")
+func newExamEvent(rules CategoryRules, exam webuntis.Exam) Event {
+	course := getExamCourse(exam)
+
+	var classes, teachers []string
+	for _, class := range exam.Classes {
+		classes = append(classes, class.DisplayName)
 	}
-	eventsJson, err := cache.Load()
-	if err != nil {
-		return events, err
+	for _, teacher := range exam.Teachers {
+		teachers = append(teachers, teacher.ShortName)
+	}
+
+	return Event{
+		Id:          getEventId(ExamSource, int64(exam.Id), exam.Name, exam.Start.String()),
+		Title:       generateExamTitle(exam),
+		Description: generateExamDescription(exam),
+		Category:    getExamCategory(rules, exam),
+		Date:        exam.Start.Format("2006-01-02"),
+		FullDay:     false,
+		Start:       exam.Start.Time,
+		End:         exam.End.Time,
+		Location:    formatLocations(getExamRooms(exam)),
+		Sources:     []EventSource{ExamSource},
+		Classes:     classes,
+		Teachers:    teachers,
+		Rooms:       getExamRooms(exam),
+		Subject:     course.Subject.String(),
+		Course:      course.Code,
+		ExamType:    getExamType(exam),
+		Status:      getEventStatus(exam.Name, exam.Text),
+		Modified:    time.Now(),
 	}
-	err = json.Unmarshal(eventsJson, &events)
-
-	return events, err
 }
 
-func sortEvents(events []Event) {
-	slices.SortFunc(events, func(a, b Event) int {
-		if a.Start.Before(b.Start) {
-			return -1
-		} else if b.Start.Before(a.Start) {
-			return 1
-		}
-		if a.FullDay && !b.FullDay {
-			return -1
-		} else if b.FullDay && !a.FullDay {
-			return 1
-		}
-		if a.End.Before(b.End) {
-			return -1
-		} else if b.End.Before(a.End) {
-			return 1
-		}
-		if a.Title < b.Title {
-			return -1
-		}
-		return 1
-	})
-
+func newCalendarEvent(rules CategoryRules, calendarEvent webuntis.CalendarEvent) Event {
+	var rooms []string
+	if calendarEvent.Location != "" {
+		rooms = []string{calendarEvent.Location}
+	}
+
+	return Event{
+		Id:          getEventId(CalendarSource, calendarEvent.Id, calendarEvent.Name, calendarEvent.Start.String()),
+		Title:       calendarEvent.Name,
+		Description: calendarEvent.Notes,
+		Category:    getCalendarEventCategory(rules, calendarEvent),
+		Date:        calendarEvent.Date,
+		FullDay:     calendarEvent.FullDay,
+		Start:       calendarEvent.Start,
+		End:         calendarEvent.End,
+		Location:    formatLocation(calendarEvent.Location),
+		Sources:     []EventSource{CalendarSource},
+		Rooms:       rooms,
+		Status:      getEventStatus(calendarEvent.Name, calendarEvent.Notes),
+		Modified:    time.Now(),
+	}
 }
 
-func generateExamTitle(exam webuntis.Exam) string {
-	// exam type
-	examType := ""
-	switch exam.Type.ShortName {
-	case "LEK-Test":
-		if AnyContain([]string{exam.Name, exam.Text}, "Test", true) {
-			examType = "Test"
-		} else {
-			examType = "LEK"
-		}
-	default:
-		examType = exam.Type.ShortName
-	}
-	if examType == "" {
-		for _, currentType := range []string{"Klausur", "Test", "LEK"} {
-			if AnyContain([]string{exam.Name, exam.Text}, currentType, true) {
-				examType = currentType
-			}
-		}
+func newTimetableEvent(rules CategoryRules, timetableEvent webuntis.TimetableEvent) Event {
+	title := fmt.Sprintf("%s %s %s", timetableEvent.Title, FormatClasses(ParseClasses(timetableEvent.Classes)), formatTeachers(timetableEvent.Teachers, ""))
+
+	return Event{
+		// timetable events have no id of their own, classes are left out as they are merged across lessons
+		Id:       getEventId(TimetableSource, 0, timetableEvent.Title, timetableEvent.Start.String(), timetableEvent.End.String()),
+		Title:    title,
+		Category: getTimetableEventCategory(rules, timetableEvent),
+		Date:     timetableEvent.Start.Format("2006-01-02"),
+		FullDay:  false,
+		Start:    timetableEvent.Start,
+		End:      timetableEvent.End,
+		Sources:  []EventSource{TimetableSource},
+		Classes:  timetableEvent.Classes,
+		Teachers: timetableEvent.Teachers,
+		Status:   getEventStatus(timetableEvent.Title),
+		Modified: time.Now(),
 	}
+}
 
-	// exam class or grade level
-	var examClasses []string
-	for _, class := range exam.Classes {
-		examClasses = append(examClasses, class.DisplayName)
+// get the id of an event from its WebUntis id or, if there is none, from a hash of its identifying parts
+func getEventId(source EventSource, id int64, parts ...string) string {
+	if id != 0 {
+		return fmt.Sprintf("%s-%d", source, id)
 	}
-	examClass := getClassesOrGradeLevels(examClasses)
+	hash := fnv.New64a()
+	hash.Write([]byte(strings.Join(parts, "\x00")))
+	return fmt.Sprintf("%s-%x", source, hash.Sum64())
+}
 
-	// exam subject
-	examSubject := getExamSubject(exam).String()
+// cancellations are only announced in the title or notes of an event
+func getEventStatus(texts ...string) EventStatus {
+	if AnyContainAny(texts, []string{"entfällt", "fällt aus", "abgesagt"}, true) {
+		return EventCancelled
+	}
+	return EventScheduled
+}
 
-	// exam course type
-	examCourseType := ""
-	for _, courseType := range []string{"GK", "LK"} {
-		subject := ""
-		if len(exam.Subject.ShortName) >= 2 {
-			subject = exam.Subject.ShortName[:2]
-		}
-		if AnyContain([]string{exam.Name, exam.Text, subject}, courseType, false) {
-			examCourseType = courseType
-			break
+// get the events of a source for every day in the range, fetching only the days missing in the cache
+func getDailyEvents(source, prefix string, start, end time.Time, refresh bool, fetch func(start, end time.Time) ([]Event, error)) (events []Event, err error) {
+	var missingDays []time.Time
+	for day := start; !day.After(end); day = day.Add(24 * time.Hour) {
+		var dayEvents []Event
+		if !refresh && loadCached(source, getDayCacheKey(prefix, day), &dayEvents) == nil {
+			events = append(events, dayEvents...)
+		} else {
+			missingDays = append(missingDays, day)
 		}
 	}
 
-	// exam teacher
-	examTeachers := []string{}
-	for _, teacher := range exam.Teachers {
-		teacherName := teacher.LongName
+	for _, dayRange := range getDayRanges(missingDays) {
+		// identical concurrent requests share a single upstream fetch
+		flightKey := source + "/" + getDayCacheKey(prefix, dayRange[0]) + "-" + dayRange[1].Format(dateFormat)
+		fetchedEvents, err := fetchGroup.do(flightKey, func() (fetchedEvents []Event, err error) {
+			fetchedEvents, err = fetch(dayRange[0], dayRange[1])
+			if err != nil {
+				return fetchedEvents, err
+			}
+			fetchedEvents = expandMultiDayEvents(fetchedEvents)
+			// personal views only contain events already tracked by the other sources
+			if source != personalCache {
+				trackChanges(source, prefix, dayRange[0], dayRange[1], fetchedEvents)
+			} else {
+				keepModified(source, prefix, dayRange[0], dayRange[1], fetchedEvents)
+			}
 
-		switch teacher.ShortName {
-		case "UrSoF":
-			teacherName = "Urschel"
+			// days without events are cached as well, so they are not requested again
+			for day := dayRange[0]; !day.After(dayRange[1]); day = day.Add(24 * time.Hour) {
+				writeCached(source, getDayCacheKey(prefix, day), filterEventsByDate(fetchedEvents, day))
+			}
+			return fetchedEvents, nil
+		})
+		if err != nil {
+			return events, err
 		}
 
-		examTeachers = append(examTeachers, teacherName)
+		for day := dayRange[0]; !day.After(dayRange[1]); day = day.Add(24 * time.Hour) {
+			events = append(events, filterEventsByDate(fetchedEvents, day)...)
+		}
 	}
-	examTeacher := getTeacher(examTeachers)
 
-	// combine elements into title
-	title := strings.Join([]string{examType, examClass, examSubject, examCourseType, examTeacher}, " ")
-	title = strings.ReplaceAll(strings.TrimSpace(title), "  ", " ")
-	return title
+	return events, nil
 }
 
-func generateExamDescription(exam webuntis.Exam) string {
-	var usedWords []string
-	title := generateExamTitle(exam)
-
-	if Contains(title, "GK", false) {
-		usedWords = append(usedWords, []string{"Grund", "Grundkurs"}...)
-	} else if Contains(title, "LK", false) {
-		usedWords = append(usedWords, []string{"Leistungs", "Leistungskurs"}...)
-	}
-	if Contains(title, "KA", false) {
-		usedWords = append(usedWords, "Klassenarbeit")
-	}
-	usedWords = append(usedWords, getExamSubject(exam).Variants()...)
-	usedWords = append(usedWords, strings.Split(title, " ")...)
+// add an event for every day covered by events spanning several days
+func expandMultiDayEvents(events []Event) (expanded []Event) {
+	expanded = []Event{}
+	for _, event := range events {
+		days := getEventDays(event)
+		if len(days) <= 1 {
+			expanded = append(expanded, event)
+			continue
+		}
 
-	if isUseful(exam.Name, usedWords) && isUseful(exam.Text, usedWords) && len(exam.Name)+len(exam.Text) < 75 {
-		return exam.Name + " - " + exam.Text
+		for i, day := range days {
+			date := day.Format("2006-01-02")
+			// WebUntis may list the event on each of its days already
+			if slices.ContainsFunc(expanded, func(other Event) bool {
+				return other.Id == event.Id && other.Date == date
+			}) {
+				continue
+			}
+			dayEvent := event
+			dayEvent.Date = date
+			dayEvent.Day = i + 1
+			dayEvent.DayCount = len(days)
+			expanded = append(expanded, dayEvent)
+		}
 	}
+	return expanded
+}
 
-	if isUseful(exam.Text, usedWords) {
-		return exam.Text
+// get every day from the start to the end of an event
+func getEventDays(event Event) (days []time.Time) {
+	end := event.End
+	// events ending at midnight do not cover the following day
+	if end.After(event.Start) && end.Equal(time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())) {
+		end = end.Add(-time.Nanosecond)
 	}
+	lastDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())
 
-	if isUseful(exam.Name, usedWords) {
-		return exam.Name
+	for day := time.Date(event.Start.Year(), event.Start.Month(), event.Start.Day(), 0, 0, 0, 0, event.Start.Location()); !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
 	}
-
-	return ""
+	return days
 }
 
-func isUseful(text string, usedWords []string) bool {
-	if len(text) == 0 {
-		return false
-	}
-	usefulText := text
-	for _, word := range usedWords {
-		usefulText = strings.ReplaceAll(usefulText, word, "")
-	}
-	if float32(len(strings.Trim(usefulText, " .,:;-/&0123456789")))/float32(len(text)) < 0.4 {
-		return false
+func filterEventsByDate(events []Event, day time.Time) (dayEvents []Event) {
+	dayEvents = []Event{}
+	for _, event := range events {
+		if event.Date == day.Format("2006-01-02") {
+			dayEvents = append(dayEvents, event)
+		}
 	}
-	return true
+	return dayEvents
 }
 
-func getExamSubject(exam webuntis.Exam) Subject {
-	for i := 0; i < int(EmptySubject)-1; i++ {
-		subject := Subject(i)
-		if exam.Subject.ShortName != "" {
-			shortName := exam.Subject.ShortName[:2]
-			if subject == Seminarkurs && len(exam.Subject.ShortName) >= 4 {
-				shortName = exam.Subject.ShortName[2:4]
-			}
-			if subject.Short() == shortName {
-				return subject
-			}
+// group consecutive days into ranges of first and last day
+func getDayRanges(days []time.Time) (dayRanges [][2]time.Time) {
+	for _, day := range days {
+		if len(dayRanges) > 0 && dayRanges[len(dayRanges)-1][1].Add(24*time.Hour).Equal(day) {
+			dayRanges[len(dayRanges)-1][1] = day
 		} else {
-			if AnyContainAny([]string{exam.Name, exam.Text}, subject.Variants(), false) {
-				return subject
-			}
+			dayRanges = append(dayRanges, [2]time.Time{day, day})
 		}
 	}
-	return EmptySubject
+	return dayRanges
 }
 
-func getClassesOrGradeLevels(classes []string) string {
-	class := ""
-	for i, class := range classes {
-		classes[i] = strings.Replace(class, "Jhg", "Jg", 1)
+func getPerson(session *lazySession, person string, personType webuntis.PersonType) (personData webuntis.UntisValue, err error) {
+	persons, err := getPersons(session, personType)
+	if err != nil {
+		return personData, err
 	}
-	slices.Sort(classes)
-	classes = slices.Compact(classes)
-	if len(classes) > 2 {
-		gradeLevels := []string{}
-		for _, class := range classes {
-			gradeLevels = append(gradeLevels, getClassGradeLevel(class))
-		}
-		gradeLevels = slices.Compact(gradeLevels)
-		if len(gradeLevels) == 1 {
-			class = "Jg" + gradeLevels[0]
-		} else {
-			class = "Jg " + strings.Join(gradeLevels, ", ")
+	for _, currPerson := range persons {
+		if currPerson.DisplayName == person {
+			return currPerson, nil
 		}
 	}
-	if class == "" {
-		class = strings.Join(classes, ", ")
-	}
-	return class
+	return personData, errors.New("error: that " + string(personType) + " does not exist")
 }
 
-func getClassGradeLevel(class string) (gradeLevel string) {
-	re := regexp.MustCompile("[0-9]+")
-	return re.FindString(class)
+// get all teachers or classes, students are not listed as the API is public
+func GetPersons(personType webuntis.PersonType) (persons []webuntis.UntisValue, err error) {
+	if personType != webuntis.TypeTeacher && personType != webuntis.TypeClass {
+		return persons, errors.New("error: only teachers and classes can be listed")
+	}
+
+	session := &lazySession{}
+	defer session.logout()
+
+	return getPersons(session, personType)
 }
 
-func getTeacher(teachers []string) string {
-	if len(teachers) > 2 {
-		return ""
+func getPersons(session *lazySession, personType webuntis.PersonType) (persons []webuntis.UntisValue, err error) {
+	cacheKey := string(personType) + "s"
+	err = loadCached(masterDataCache, cacheKey, &persons)
+	if err == nil {
+		return persons, nil
 	}
-	return strings.Join(teachers, ", ")
-}
 
-func getCalendarEventCategory(event webuntis.CalendarEvent) EventCategory {
-	switch event.Calendar {
-	case "Termine Jahrgang 7-9":
-		return SekIEvent
-	case "Termine Jahrgang 10 und Oberstufe":
-		return SekIIEvent
-	case "Lernende":
-		return StudentEvent
-	case "Lehrkräfte":
-		return TeacherEvent
-	case "Öffentlich":
-		if Contains(event.Name, "AG", false) {
-			return AGEvent
-		} else {
-			return PublicEvent
+	return personsGroup.do(cacheKey, func() (persons []webuntis.UntisValue, err error) {
+		untis, err := session.get()
+		if err != nil {
+			return persons, err
 		}
-	default:
-		return PublicEvent
-	}
+		persons, err = untis.GetPersons(personType)
+		if err != nil {
+			return persons, err
+		}
+		writeCached(masterDataCache, cacheKey, persons)
+
+		return persons, nil
+	})
 }
 
-func formatLocation(room string) string {
-	switch room {
-	case "Turnhalle":
-		return "TH"
-	case "SHA":
-		return "TH (A)"
-	case "SHB":
-		return "TH (B)"
-	case "SHC":
-		return "TH (C)"
+func getDayCacheKey(prefix string, day time.Time) string {
+	if prefix == "" {
+		return day.Format(dateFormat)
 	}
-	return room
+	return prefix + "/" + day.Format(dateFormat)
+}
+
+func sortEvents(events []Event) {
+	slices.SortFunc(events, func(a, b Event) int {
+		if a.Start.Before(b.Start) {
+			return -1
+		} else if b.Start.Before(a.Start) {
+			return 1
+		}
+		if a.FullDay && !b.FullDay {
+			return -1
+		} else if b.FullDay && !a.FullDay {
+			return 1
+		}
+		if a.End.Before(b.End) {
+			return -1
+		} else if b.End.Before(a.End) {
+			return 1
+		}
+		if a.Title < b.Title {
+			return -1
+		}
+		return 1
+	})
+
 }
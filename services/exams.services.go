@@ -0,0 +1,362 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mcg-dallgow/mcg-display/services/webuntis"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+const defaultExamTitleTemplate string = "{{.Type}} {{.Classes}} {{.Subject}} {{.CourseLevel}} {{.Teachers}}"
+const defaultExamDescriptionTemplate string = "{{.Details}}"
+const defaultMaxDescriptionLength int = 75
+
+// limits for preview templates, as they are given by users
+const maxPreviewTemplateLength int = 500
+const maxPreviewOutputLength int = 2000
+
+// raw exams are cached next to the exam events of a day
+const rawExamsPrefix string = "raw"
+
+// templates for exam titles and descriptions, configured in config/exams.json
+type ExamTemplates struct {
+	Title                string `json:"title"`
+	Description          string `json:"description"`
+	MaxDescriptionLength int    `json:"maxDescriptionLength"`
+
+	titleTemplate       *template.Template
+	descriptionTemplate *template.Template
+}
+
+// structured exam data available in the templates
+type ExamFields struct {
+	Type         string
+	Classes      string
+	ClassList    []string
+	Subject      string
+	SubjectShort string
+	CourseLevel  string
+	Course       Course
+	Teachers     string
+	TeacherList  []string
+	Rooms        string
+	RoomList     []string
+	Name         string
+	Text         string
+	// parts of the exam name and text that are not already contained in the title
+	Details string
+}
+
+type ExamPreview struct {
+	Id          int        `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Fields      ExamFields `json:"fields"`
+}
+
+var examTemplates = getDefaultExamTemplates()
+
+// load the exam templates at start-up, falling back to the default templates
+func InitExamTemplates() {
+	templates := getDefaultExamTemplates()
+	err := loadConfig("exams", &templates)
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("loading exam templates failed: %v", err)
+		templates = getDefaultExamTemplates()
+	}
+	if err = templates.compile(); err != nil {
+		log.Printf("compiling exam templates failed: %v", err)
+		templates = getDefaultExamTemplates()
+	}
+	examTemplates = templates
+}
+
+func getDefaultExamTemplates() (templates ExamTemplates) {
+	templates = ExamTemplates{
+		Title:                defaultExamTitleTemplate,
+		Description:          defaultExamDescriptionTemplate,
+		MaxDescriptionLength: defaultMaxDescriptionLength,
+	}
+	templates.compile()
+	return templates
+}
+
+func (templates *ExamTemplates) compile() (err error) {
+	templates.titleTemplate, err = template.New("title").Parse(templates.Title)
+	if err != nil {
+		return err
+	}
+	templates.descriptionTemplate, err = template.New("description").Parse(templates.Description)
+	return err
+}
+
+// get the title and description generated for an exam, optionally using another title template
+func GetExamPreview(id int, start, end time.Time, titleTemplate string) (preview ExamPreview, err error) {
+	templates := examTemplates
+	if titleTemplate != "" {
+		// the template is given by unauthenticated users
+		if len(titleTemplate) > maxPreviewTemplateLength {
+			return preview, errors.New("error: title template is too long")
+		}
+		templates.Title = titleTemplate
+		if err = templates.compile(); err != nil {
+			return preview, err
+		}
+	}
+
+	session := &lazySession{}
+	defer session.logout()
+	updateTeacherDirectory(session)
+
+	exams, err := getRawExams(session, start, limitDateRange(start, end))
+	if err != nil {
+		return preview, err
+	}
+
+	for _, exam := range exams {
+		if exam.Id == id {
+			fields := templates.getFields(exam)
+			title := templates.generateTitle(exam)
+			if titleTemplate != "" {
+				// errors of custom templates are reported instead of resulting in an empty title
+				writer := &limitedWriter{limit: maxPreviewOutputLength}
+				if err = templates.titleTemplate.Execute(writer, fields); err != nil {
+					return preview, err
+				}
+				title = formatTemplateOutput(writer.builder.String())
+			}
+			return ExamPreview{
+				Id:          exam.Id,
+				Title:       title,
+				Description: templates.generateDescription(exam),
+				Fields:      fields,
+			}, nil
+		}
+	}
+	return preview, errors.New("error: that exam does not exist")
+}
+
+// get the exams as returned by WebUntis, which are cached together with the exam events
+func getRawExams(session *lazySession, start, end time.Time) (exams []webuntis.Exam, err error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		exams = []webuntis.Exam{}
+		complete := true
+		for day := start; !day.After(end); day = day.Add(24 * time.Hour) {
+			var dayExams []webuntis.Exam
+			if loadCached(examsCache, getDayCacheKey(rawExamsPrefix, day), &dayExams) != nil {
+				complete = false
+				break
+			}
+			exams = append(exams, dayExams...)
+		}
+		if complete {
+			return exams, nil
+		}
+		// fetching the exam events writes the raw exams of every day as well
+		if _, err = getExams(session, start, end, true); err != nil {
+			return exams, err
+		}
+	}
+	return exams, errors.New("error: exams could not be loaded")
+}
+
+func generateExamTitle(exam webuntis.Exam) string {
+	return examTemplates.generateTitle(exam)
+}
+
+func generateExamDescription(exam webuntis.Exam) string {
+	return examTemplates.generateDescription(exam)
+}
+
+func (templates *ExamTemplates) generateTitle(exam webuntis.Exam) string {
+	return executeTemplate(templates.titleTemplate, templates.getFields(exam))
+}
+
+func (templates *ExamTemplates) generateDescription(exam webuntis.Exam) string {
+	fields := templates.getFields(exam)
+	fields.Details = templates.getDetails(exam, templates.generateTitle(exam))
+	return executeTemplate(templates.descriptionTemplate, fields)
+}
+
+// get the type of an exam, e.g. "KA", which is only given in the name or text of some exams
+func getExamType(exam webuntis.Exam) (examType string) {
+	switch exam.Type.ShortName {
+	case "LEK-Test":
+		if AnyContain([]string{exam.Name, exam.Text}, "Test", true) {
+			return "Test"
+		}
+		return "LEK"
+	}
+	examType = exam.Type.ShortName
+	if examType == "" {
+		for _, currentType := range []string{"Klausur", "Test", "LEK"} {
+			if AnyContain([]string{exam.Name, exam.Text}, currentType, true) {
+				examType = currentType
+			}
+		}
+	}
+	return examType
+}
+
+func (templates *ExamTemplates) getFields(exam webuntis.Exam) (fields ExamFields) {
+	fields.Name = exam.Name
+	fields.Text = exam.Text
+
+	fields.Type = getExamType(exam)
+
+	// exam class or grade level
+	for _, class := range exam.Classes {
+		fields.ClassList = append(fields.ClassList, class.DisplayName)
+	}
+	fields.Classes = FormatClasses(ParseClasses(fields.ClassList))
+
+	// exam subject and course level
+	fields.Course = getExamCourse(exam)
+	fields.Subject = fields.Course.Subject.String()
+	fields.SubjectShort = fields.Course.Subject.Short()
+	fields.CourseLevel = fields.Course.Level.String()
+	if fields.CourseLevel == fields.Subject {
+		fields.CourseLevel = ""
+	}
+
+	// exam teachers with their configured display names
+	teachers := []string{}
+	for _, teacher := range exam.Teachers {
+		if hasTeacher(teacher.ShortName) {
+			teachers = append(teachers, teacher.ShortName)
+		} else {
+			teachers = append(teachers, teacher.LongName)
+		}
+	}
+	fields.TeacherList = getTeacherNames(teachers, "")
+	fields.Teachers = formatTeachers(teachers, "")
+
+	// exam rooms
+	for _, room := range getExamRooms(exam) {
+		fields.RoomList = append(fields.RoomList, formatLocation(room))
+	}
+	fields.Rooms = strings.Join(fields.RoomList, ", ")
+
+	return fields
+}
+
+// get the parts of the exam name and text that add information to the title
+func (templates *ExamTemplates) getDetails(exam webuntis.Exam, title string) string {
+	var usedWords []string
+
+	if Contains(title, "GK", false) {
+		usedWords = append(usedWords, []string{"Grund", "Grundkurs"}...)
+	} else if Contains(title, "LK", false) {
+		usedWords = append(usedWords, []string{"Leistungs", "Leistungskurs"}...)
+	}
+	if Contains(title, "KA", false) {
+		usedWords = append(usedWords, "Klassenarbeit")
+	}
+	usedWords = append(usedWords, getExamSubject(exam).Variants()...)
+	usedWords = append(usedWords, strings.Split(title, " ")...)
+
+	if isUseful(exam.Name, usedWords) && isUseful(exam.Text, usedWords) && len(exam.Name)+len(exam.Text) < templates.MaxDescriptionLength {
+		return exam.Name + " - " + exam.Text
+	}
+
+	if isUseful(exam.Text, usedWords) {
+		return exam.Text
+	}
+
+	if isUseful(exam.Name, usedWords) {
+		return exam.Name
+	}
+
+	return ""
+}
+
+// execute a template and remove duplicate whitespace left by empty fields
+func executeTemplate(tmpl *template.Template, data any) string {
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		log.Printf("executing template %s failed: %v", tmpl.Name(), err)
+		return ""
+	}
+	return formatTemplateOutput(builder.String())
+}
+
+// collapse whitespace and remove empty lines of a template's output
+func formatTemplateOutput(text string) string {
+	lines := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writer failing once the limit is exceeded, which stops the template execution
+type limitedWriter struct {
+	builder strings.Builder
+	limit   int
+}
+
+func (writer *limitedWriter) Write(data []byte) (n int, err error) {
+	if writer.builder.Len()+len(data) > writer.limit {
+		return 0, errors.New("error: template output is too long")
+	}
+	return writer.builder.Write(data)
+}
+
+func isUseful(text string, usedWords []string) bool {
+	if len(text) == 0 {
+		return false
+	}
+	usefulText := text
+	for _, word := range usedWords {
+		usefulText = strings.ReplaceAll(usefulText, word, "")
+	}
+	if float32(len(strings.Trim(usefulText, " .,:;-/&0123456789")))/float32(len(text)) < 0.4 {
+		return false
+	}
+	return true
+}
+
+func getExamRooms(exam webuntis.Exam) (rooms []string) {
+	for _, room := range exam.Rooms {
+		rooms = append(rooms, room.ShortName)
+	}
+	return rooms
+}
+
+func getExamSubject(exam webuntis.Exam) (subject Subject) {
+	return getExamCourse(exam).Subject
+}
+
+// get the course of an exam from its course code, falling back to the exam name and text
+func getExamCourse(exam webuntis.Exam) (course Course) {
+	course, err := ParseCourse(exam.Subject.ShortName)
+	if err == nil {
+		if course.Level == NoCourseLevel {
+			course.Level = getCourseLevelFromTexts([]string{exam.Name, exam.Text})
+		}
+		return course
+	}
+
+	course = Course{Code: exam.Subject.ShortName}
+	if exam.Subject.ShortName == "" {
+		course.Subject, _ = getSubjectFromTexts([]string{exam.Name, exam.Text})
+	}
+	course.Level = getCourseLevelFromTexts([]string{exam.Name, exam.Text})
+	return course
+}
+
+func getCourseLevelFromTexts(texts []string) CourseLevel {
+	for _, level := range []CourseLevel{BasicCourse, AdvancedCourse} {
+		if AnyContain(texts, string(level), false) {
+			return level
+		}
+	}
+	return NoCourseLevel
+}
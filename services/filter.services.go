@@ -0,0 +1,134 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+// restricts the events shown on a display
+type EventFilter struct {
+	Audience Audience
+	// if set, only events of these categories are shown
+	Include []EventCategory
+	Exclude []EventCategory
+	// overrides the configured format of teacher names
+	TeacherFormat TeacherFormat
+}
+
+var displayProfiles = map[string]DisplayProfile{}
+
+// load the display profiles at start-up
+func InitDisplays() {
+	var config struct {
+		Displays map[string]DisplayProfile `json:"displays"`
+	}
+	err := loadConfig("displays", &config)
+	if err == nil {
+		err = validateDisplays(config.Displays)
+	}
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("loading display profiles failed: %v", err)
+		}
+		return
+	}
+	displayProfiles = config.Displays
+}
+
+// check that every display has a known audience, teacher format and categories
+func validateDisplays(displays map[string]DisplayProfile) (err error) {
+	for name, profile := range displays {
+		if profile.Audience != "" && !profile.Audience.IsValid() {
+			return errors.New("error: unknown audience " + string(profile.Audience) + " of display " + name)
+		}
+		if profile.TeacherFormat != "" && !profile.TeacherFormat.IsValid() {
+			return errors.New("error: unknown teacher format " + string(profile.TeacherFormat) + " of display " + name)
+		}
+		if _, err := parseEventCategories(append(slices.Clone(profile.Include), profile.Exclude...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// build the filter of a display profile combined with categories given in the query,
+// the audience applies if the display has none
+func GetEventFilter(display, include, exclude string, audience Audience) (filter EventFilter, err error) {
+	filter.Audience = audience
+	profile := DisplayProfile{}
+	if display != "" {
+		var ok bool
+		profile, ok = displayProfiles[display]
+		if !ok {
+			return filter, errors.New("error: that display does not exist")
+		}
+		if profile.Audience != "" {
+			filter.Audience = profile.Audience
+		}
+		filter.TeacherFormat = profile.TeacherFormat
+	}
+
+	includeKeys := profile.Include
+	if include != "" {
+		includeKeys = strings.Split(include, ",")
+	}
+	excludeKeys := profile.Exclude
+	if exclude != "" {
+		excludeKeys = append(slices.Clone(excludeKeys), strings.Split(exclude, ",")...)
+	}
+
+	if filter.Include, err = parseEventCategories(includeKeys); err != nil {
+		return filter, err
+	}
+	if filter.Exclude, err = parseEventCategories(excludeKeys); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// audiences ordered from the least to the most events they may see
+var audiences = []Audience{PublicAudience, StudentAudience, StaffAudience}
+
+// restrict the filter to events the audience may see, e.g. for routes anyone can request
+func LimitAudience(filter EventFilter, audience Audience) EventFilter {
+	if filter.Audience == "" || slices.Index(audiences, filter.Audience) > slices.Index(audiences, audience) {
+		filter.Audience = audience
+	}
+	return filter
+}
+
+func parseEventCategories(keys []string) (categories []EventCategory, err error) {
+	for _, key := range keys {
+		category, err := ParseEventCategory(strings.TrimSpace(key))
+		if err != nil {
+			return categories, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, nil
+}
+
+func (filter *EventFilter) Allows(event Event) bool {
+	if filter.Audience != "" && !filter.Audience.Allows(event.Category) {
+		return false
+	}
+	if len(filter.Include) > 0 && !slices.Contains(filter.Include, event.Category) {
+		return false
+	}
+	return !slices.Contains(filter.Exclude, event.Category)
+}
+
+func filterEvents(events []Event, filter EventFilter) (filtered []Event) {
+	filtered = []Event{}
+	for _, event := range events {
+		if filter.Allows(event) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/mcg-dallgow/mcg-display/services/webuntis"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+// rolling window of feeds, can be overwritten in .env
+const defaultFeedPastDays int = 30
+const defaultFeedFutureDays int = 180
+
+const feedDomain string = "mcg-display"
+const feedTimezone string = "Europe/Berlin"
+
+// person whose events are published under a secret token
+type FeedToken struct {
+	Type webuntis.PersonType `json:"type"`
+	Name string              `json:"name"`
+}
+
+var feedTokens = map[string]FeedToken{}
+var feedGroup flightGroup[string]
+
+// load the tokens of personal feeds from config/feeds.json
+func InitFeeds() {
+	var config struct {
+		Tokens map[string]FeedToken `json:"tokens"`
+	}
+	err := loadConfig("feeds", &config)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("loading feed tokens failed: %v", err)
+		}
+		return
+	}
+	feedTokens = config.Tokens
+}
+
+// get the teacher or student a personal feed token belongs to
+func GetFeedResource(token string) (resource Resource, err error) {
+	feedToken, ok := feedTokens[token]
+	if token == "" || !ok {
+		return resource, errors.New("error: invalid feed token")
+	}
+	return Resource{Type: feedToken.Type, Name: feedToken.Name}, nil
+}
+
+// get the range of days contained in feeds
+func GetFeedWindow() (start, end time.Time) {
+	godotenv.Load()
+
+	pastDays := defaultFeedPastDays
+	if days, err := strconv.Atoi(os.Getenv("ICAL_PAST_DAYS")); err == nil && days >= 0 {
+		pastDays = days
+	}
+	futureDays := defaultFeedFutureDays
+	if days, err := strconv.Atoi(os.Getenv("ICAL_FUTURE_DAYS")); err == nil && days >= 0 {
+		futureDays = days
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return today.AddDate(0, 0, -pastDays), today.AddDate(0, 0, futureDays)
+}
+
+// get the rendered feed of the events within the feed window, feeds are cached as long as their events
+func GetFeed(name string, resources []Resource, filter EventFilter) (feed string, err error) {
+	start, end := GetFeedWindow()
+	key := getFeedCacheKey(name, resources, filter, start, end)
+	if updated, err := loadCachedData(feedCache, key, &feed); err == nil && time.Since(updated) <= getFeedTTL() {
+		return feed, nil
+	}
+
+	return feedGroup.do(key, func() (feed string, err error) {
+		events, err := GetEvents(start, end, resources, filter)
+		if err != nil {
+			return feed, err
+		}
+		feed = FormatICalendar(name, events)
+		writeCached(feedCache, key, feed)
+		return feed, nil
+	})
+}
+
+// feeds expire with the first of the sources they are built from
+func getFeedTTL() (ttl time.Duration) {
+	ttl = cacheTTLs[examsCache]
+	for _, source := range []string{calendarCache, timetableCache, personalCache} {
+		ttl = min(ttl, cacheTTLs[source])
+	}
+	return ttl
+}
+
+// the key is hashed, as it combines the names of persons and the whole filter
+func getFeedCacheKey(name string, resources []Resource, filter EventFilter, start, end time.Time) string {
+	hash := fnv.New64a()
+	fmt.Fprint(hash, name, resources, filter, start.Format(dateFormat), end.Format(dateFormat))
+	return fmt.Sprintf("%x", hash.Sum64())
+}
+
+// format events as an iCalendar feed according to RFC 5545
+func FormatICalendar(name string, events map[string][]Event) string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Marie-Curie-Gymnasium Dallgow-Döberitz//MCG Display//DE",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escapeICalText(name),
+		"X-WR-TIMEZONE:" + feedTimezone,
+	}
+	lines = append(lines, getICalTimezone()...)
+
+	// events spanning several days are contained once for every day, but published once
+	published := make(map[string]bool)
+	start, end := getFeedDates(events)
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		for _, event := range events[day.Format("2006-01-02")] {
+			if event.Id != "" && published[event.Id] {
+				continue
+			}
+			published[event.Id] = true
+			lines = append(lines, formatICalEvent(event)...)
+		}
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	var builder strings.Builder
+	for _, line := range lines {
+		builder.WriteString(foldICalLine(line))
+	}
+	return builder.String()
+}
+
+func formatICalEvent(event Event) (lines []string) {
+	modified := event.Modified
+	if modified.IsZero() {
+		modified = time.Now()
+	}
+	uid := event.Id
+	if uid == "" {
+		uid = getEventId(TimetableSource, 0, event.Title, event.Start.String())
+	}
+
+	lines = []string{
+		"BEGIN:VEVENT",
+		"UID:" + uid + "@" + feedDomain,
+		"DTSTAMP:" + modified.UTC().Format("20060102T150405Z"),
+		"LAST-MODIFIED:" + modified.UTC().Format("20060102T150405Z"),
+	}
+	if event.FullDay {
+		days := getEventDays(event)
+		if len(days) == 0 {
+			days = []time.Time{parseEventDate(event)}
+		}
+		// the end of full day events is exclusive
+		lines = append(lines,
+			"DTSTART;VALUE=DATE:"+days[0].Format("20060102"),
+			"DTEND;VALUE=DATE:"+days[len(days)-1].AddDate(0, 0, 1).Format("20060102"),
+		)
+	} else {
+		// event times are local times of the school
+		lines = append(lines,
+			"DTSTART;TZID="+feedTimezone+":"+event.Start.Format("20060102T150405"),
+			"DTEND;TZID="+feedTimezone+":"+event.End.Format("20060102T150405"),
+		)
+	}
+	lines = append(lines, "SUMMARY:"+escapeICalText(event.Title))
+	if event.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+escapeICalText(event.Description))
+	}
+	if event.Location != "" {
+		lines = append(lines, "LOCATION:"+escapeICalText(event.Location))
+	}
+	lines = append(lines, "CATEGORIES:"+escapeICalText(event.Category.String()))
+	if event.Status == EventCancelled {
+		lines = append(lines, "STATUS:CANCELLED")
+	} else {
+		lines = append(lines, "STATUS:CONFIRMED")
+	}
+	lines = append(lines, "END:VEVENT")
+
+	return lines
+}
+
+// timezone definition required for the local times of timed events
+func getICalTimezone() []string {
+	return []string{
+		"BEGIN:VTIMEZONE",
+		"TZID:" + feedTimezone,
+		"BEGIN:DAYLIGHT",
+		"TZOFFSETFROM:+0100",
+		"TZOFFSETTO:+0200",
+		"TZNAME:CEST",
+		"DTSTART:19700329T020000",
+		"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU",
+		"END:DAYLIGHT",
+		"BEGIN:STANDARD",
+		"TZOFFSETFROM:+0200",
+		"TZOFFSETTO:+0100",
+		"TZNAME:CET",
+		"DTSTART:19701025T030000",
+		"RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+	}
+}
+
+func getFeedDates(events map[string][]Event) (start, end time.Time) {
+	for date := range events {
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			continue
+		}
+		if start.IsZero() || day.Before(start) {
+			start = day
+		}
+		if end.IsZero() || day.After(end) {
+			end = day
+		}
+	}
+	return start, end
+}
+
+func escapeICalText(text string) string {
+	replacer := strings.NewReplacer("\\", "\\\\", ";", "\\;", ",", "\\,", "\r\n", "\\n", "\n", "\\n")
+	return replacer.Replace(text)
+}
+
+// split lines longer than 75 octets without breaking multi-byte characters
+func foldICalLine(line string) string {
+	var builder strings.Builder
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > 75 {
+			builder.WriteString("\r\n ")
+			length = 1
+		}
+		builder.WriteRune(r)
+		length += size
+	}
+	builder.WriteString("\r\n")
+	return builder.String()
+}
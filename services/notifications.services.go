@@ -0,0 +1,273 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joho/godotenv"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+type Subscriber struct {
+	Name    string `json:"name"`
+	Channel string `json:"channel"`
+	// webhook URL or email address, depending on the channel
+	Target     string   `json:"target"`
+	Teachers   []string `json:"teachers"`
+	Classes    []string `json:"classes"`
+	Categories []string `json:"categories"`
+}
+
+type NotificationChannel interface {
+	Send(subscriber Subscriber, changes []Change) (err error)
+}
+
+// only changes of these sources are sent to subscribers
+var notificationSources = []string{examsCache, calendarCache}
+
+// fields of changed events that subscribers are notified about
+var notificationFields = []string{"Date", "FullDay", "Start", "End", "Location", "Status"}
+
+// changes waiting to be sent, full queues drop further changes instead of blocking fetches
+const notificationQueueSize int = 100
+
+type notification struct {
+	source  string
+	changes []Change
+}
+
+var subscribers []Subscriber
+var notificationQueue = make(chan notification, notificationQueueSize)
+var notificationWorker sync.Once
+var notificationChannels = map[string]NotificationChannel{
+	"webhook": &WebhookChannel{Client: &http.Client{Timeout: 10 * time.Second}},
+	"email":   &EmailChannel{Host: "localhost", Port: "25", From: "mcg-display@localhost"},
+}
+
+// load subscribers from config/notifications.json and SMTP settings from .env
+func InitNotifications() {
+	godotenv.Load()
+
+	var config struct {
+		Subscribers []Subscriber `json:"subscribers"`
+	}
+	err := loadConfig("notifications", &config)
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("loading notification config failed: %v", err)
+	}
+	subscribers = config.Subscribers
+
+	email := EmailChannel{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+	if email.Host == "" {
+		email.Host = "localhost"
+	}
+	if email.Port == "" {
+		email.Port = "25"
+	}
+	if email.From == "" {
+		email.From = "mcg-display@localhost"
+	}
+	RegisterNotificationChannel("email", &email)
+
+	// a single worker sends all notifications one after another
+	notificationWorker.Do(func() {
+		go func() {
+			for notification := range notificationQueue {
+				notifyChanges(notification.source, notification.changes)
+			}
+		}()
+	})
+}
+
+func RegisterNotificationChannel(name string, channel NotificationChannel) {
+	notificationChannels[name] = channel
+}
+
+// queue the changes of a source to be sent in the background
+func queueNotification(source string, changes []Change) {
+	select {
+	case notificationQueue <- notification{source: source, changes: changes}:
+	default:
+		log.Printf("notification queue is full, dropping %d changes of %s", len(changes), source)
+	}
+}
+
+// send the changes of a source to every subscriber interested in them
+func notifyChanges(source string, changes []Change) {
+	if !slices.Contains(notificationSources, source) {
+		return
+	}
+
+	for _, subscriber := range subscribers {
+		subscriberChanges := []Change{}
+		for _, change := range changes {
+			if isNotifiable(change) && subscriber.matches(change) {
+				subscriberChanges = append(subscriberChanges, change)
+			}
+		}
+		if len(subscriberChanges) == 0 {
+			continue
+		}
+
+		channel, ok := notificationChannels[subscriber.Channel]
+		if !ok {
+			log.Printf("notification channel %s of %s does not exist", subscriber.Channel, subscriber.Name)
+			continue
+		}
+		if err := channel.Send(subscriber, subscriberChanges); err != nil {
+			log.Printf("notifying %s failed: %v", subscriber.Name, err)
+		}
+	}
+}
+
+// events are notified when they are added, moved or cancelled
+func isNotifiable(change Change) bool {
+	if change.Type != EventChanged {
+		return true
+	}
+	for _, field := range change.Fields {
+		if slices.Contains(notificationFields, field) {
+			return true
+		}
+	}
+	return false
+}
+
+func (subscriber *Subscriber) matches(change Change) bool {
+	event := change.After
+	if event == nil {
+		event = change.Before
+	}
+	texts := []string{event.Title, event.Description}
+
+	if len(subscriber.Categories) > 0 && !slices.Contains(subscriber.Categories, event.Category.Key()) {
+		return false
+	}
+	// structured data is preferred, calendar events only mention teachers and classes in their texts
+	if len(subscriber.Teachers) > 0 {
+		if len(event.Teachers) > 0 && len(getCommonItems(event.Teachers, subscriber.Teachers)) == 0 {
+			return false
+		} else if len(event.Teachers) == 0 && !AnyContainAny(texts, subscriber.Teachers, false) {
+			return false
+		}
+	}
+	if len(subscriber.Classes) > 0 {
+		if len(event.Classes) > 0 && len(getCommonItems(event.Classes, subscriber.Classes)) == 0 {
+			return false
+		} else if len(event.Classes) == 0 && !AnyContainAny(texts, subscriber.Classes, false) {
+			return false
+		}
+	}
+	return true
+}
+
+func formatChanges(changes []Change) string {
+	lines := []string{}
+	for _, change := range changes {
+		switch change.Type {
+		case EventAdded:
+			lines = append(lines, "Neu: "+formatEventSummary(*change.After))
+		case EventChanged:
+			lines = append(lines, "Geändert: "+formatEventSummary(*change.Before)+" → "+formatEventSummary(*change.After))
+		case EventRemoved:
+			lines = append(lines, "Entfällt: "+formatEventSummary(*change.Before))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatEventSummary(event Event) string {
+	summary := event.Title + ", " + event.Start.Format("02.01.2006")
+	if !event.FullDay {
+		summary += " " + event.Start.Format("15:04")
+		if !event.Start.Equal(event.End) {
+			summary += " - " + event.End.Format("15:04")
+		}
+	}
+	if event.Location != "" {
+		summary += ", " + event.Location
+	}
+	return summary
+}
+
+// channel posting the changes as JSON to the subscriber's URL
+type WebhookChannel struct {
+	Client *http.Client
+}
+
+func (channel *WebhookChannel) Send(subscriber Subscriber, changes []Change) (err error) {
+	payload, err := json.Marshal(struct {
+		Subscriber string   `json:"subscriber"`
+		Message    string   `json:"message"`
+		Changes    []Change `json:"changes"`
+	}{
+		Subscriber: subscriber.Name,
+		Message:    formatChanges(changes),
+		Changes:    changes,
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err := channel.Client.Post(subscriber.Target, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("error: webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// channel sending the changes as plain text email to the subscriber's address
+type EmailChannel struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (channel *EmailChannel) Send(subscriber Subscriber, changes []Change) (err error) {
+	if subscriber.Target == "" {
+		return errors.New("error: subscriber has no email address")
+	}
+
+	var auth smtp.Auth
+	if channel.Username != "" {
+		auth = smtp.PlainAuth("", channel.Username, channel.Password, channel.Host)
+	}
+
+	message := strings.Join([]string{
+		"From: " + channel.From,
+		"To: " + subscriber.Target,
+		"Subject: " + mime.QEncoding.Encode("utf-8", "Terminänderungen am MCG"),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		formatChanges(changes),
+	}, "\r\n")
+
+	address := net.JoinHostPort(channel.Host, channel.Port)
+	return smtp.SendMail(address, auth, channel.From, []string{subscriber.Target}, []byte(message))
+}
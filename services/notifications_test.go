@@ -0,0 +1,201 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+func getTestChanges() []Change {
+	start := time.Date(2024, 5, 6, 8, 0, 0, 0, time.UTC)
+	before := Event{Title: "KA 7a Mathe", Category: ExamEvent, Date: "2024-05-06", Start: start, End: start.Add(time.Hour), Classes: []string{"7a"}}
+	after := before
+	after.Location = "R101"
+	return []Change{
+		{Type: EventChanged, Source: examsCache, Fields: []string{"Location"}, Before: &before, After: &after},
+		{Type: EventRemoved, Source: examsCache, Before: &before},
+	}
+}
+
+func TestWebhookChannel(t *testing.T) {
+	var payload struct {
+		Subscriber string   `json:"subscriber"`
+		Message    string   `json:"message"`
+		Changes    []Change `json:"changes"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s with content type %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decoding payload failed: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	channel := WebhookChannel{Client: server.Client()}
+	subscriber := Subscriber{Name: "Website", Channel: "webhook", Target: server.URL}
+	if err := channel.Send(subscriber, getTestChanges()); err != nil {
+		t.Fatalf("sending webhook failed: %v", err)
+	}
+
+	if payload.Subscriber != "Website" {
+		t.Errorf("subscriber is %q, want %q", payload.Subscriber, "Website")
+	}
+	if len(payload.Changes) != 2 {
+		t.Errorf("got %d changes, want 2", len(payload.Changes))
+	}
+	if !strings.Contains(payload.Message, "Entfällt: KA 7a Mathe") {
+		t.Errorf("message %q does not mention the removed exam", payload.Message)
+	}
+}
+
+func TestWebhookChannelError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	channel := WebhookChannel{Client: server.Client()}
+	if err := channel.Send(Subscriber{Target: server.URL}, getTestChanges()); err == nil {
+		t.Error("expected an error for status 500")
+	}
+}
+
+// minimal SMTP server accepting a single message
+func startTestSMTPServer(t *testing.T) (host, port string, messages chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("starting SMTP listener failed: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	messages = make(chan string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case command == "DATA":
+				reply("354 end data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				messages <- data.String()
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	host, port, _ = net.SplitHostPort(listener.Addr().String())
+	return host, port, messages
+}
+
+func TestEmailChannel(t *testing.T) {
+	host, port, messages := startTestSMTPServer(t)
+
+	channel := EmailChannel{Host: host, Port: port, From: "mcg-display@localhost"}
+	subscriber := Subscriber{Name: "Oberstufe", Channel: "email", Target: "oberstufe@example.org"}
+	if err := channel.Send(subscriber, getTestChanges()); err != nil {
+		t.Fatalf("sending email failed: %v", err)
+	}
+
+	select {
+	case message := <-messages:
+		for _, expected := range []string{"To: oberstufe@example.org", "Subject: =?utf-8?q?", "Entfällt: KA 7a Mathe"} {
+			if !strings.Contains(message, expected) {
+				t.Errorf("message does not contain %q:\n%s", expected, message)
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SMTP server did not receive a message")
+	}
+}
+
+func TestEmailChannelWithoutAddress(t *testing.T) {
+	channel := EmailChannel{Host: "localhost", Port: "25"}
+	if err := channel.Send(Subscriber{Name: "Niemand"}, getTestChanges()); err == nil {
+		t.Error("expected an error for a subscriber without address")
+	}
+}
+
+type recordingChannel struct {
+	sent map[string][]Change
+}
+
+func (channel *recordingChannel) Send(subscriber Subscriber, changes []Change) (err error) {
+	channel.sent[subscriber.Name] = changes
+	return nil
+}
+
+func TestNotifyChanges(t *testing.T) {
+	channel := &recordingChannel{sent: map[string][]Change{}}
+	RegisterNotificationChannel("test", channel)
+	defer func() { subscribers = nil }()
+
+	subscribers = []Subscriber{
+		{Name: "7a", Channel: "test", Classes: []string{"7a"}},
+		{Name: "8b", Channel: "test", Classes: []string{"8b"}},
+		{Name: "Aushang", Channel: "test", Categories: []string{PublicEvent.Key()}},
+		{Name: "Prüfungen", Channel: "test", Categories: []string{ExamEvent.Key()}},
+	}
+	changes := getTestChanges()
+	// description changes are not notified
+	description := *changes[0].After
+	description.Description = "Taschenrechner mitbringen"
+	changes = append(changes, Change{Type: EventChanged, Source: examsCache, Fields: []string{"Description"}, Before: changes[0].After, After: &description})
+
+	notifyChanges(examsCache, changes)
+	if got := len(channel.sent["7a"]); got != 2 {
+		t.Errorf("7a got %d changes, want 2", got)
+	}
+	if got := len(channel.sent["Prüfungen"]); got != 2 {
+		t.Errorf("exam subscriber got %d changes, want 2", got)
+	}
+	for _, name := range []string{"8b", "Aushang"} {
+		if _, ok := channel.sent[name]; ok {
+			t.Errorf("%s was notified about unrelated changes", name)
+		}
+	}
+
+	// timetable changes are never notified
+	channel.sent = map[string][]Change{}
+	notifyChanges(timetableCache, changes)
+	if len(channel.sent) > 0 {
+		t.Error("timetable changes were notified")
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strings"
+	"text/template"
+
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+const defaultRoomFormat string = "{{.Alias}}"
+
+// rooms used if there is no config/rooms.json
+var defaultRooms = []Room{
+	{Name: "Turnhalle", Alias: "TH", LongName: "Turnhalle", Accessible: true},
+	{Name: "SHA", Alias: "TH (A)", LongName: "Sporthalle A", Accessible: true},
+	{Name: "SHB", Alias: "TH (B)", LongName: "Sporthalle B", Accessible: true},
+	{Name: "SHC", Alias: "TH (C)", LongName: "Sporthalle C", Accessible: true},
+}
+
+// rooms configured in config/rooms.json, indexed by their WebUntis name
+var rooms = getRoomIndex(defaultRooms)
+var roomFormat = template.Must(template.New("room").Parse(defaultRoomFormat))
+
+// load the room directory and display format at start-up
+func InitRooms() {
+	var directory struct {
+		Format string `json:"format"`
+		Rooms  []Room `json:"rooms"`
+	}
+	err := loadConfig("rooms", &directory)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("loading room directory failed: %v", err)
+		}
+		return
+	}
+
+	if directory.Format != "" {
+		format, err := template.New("room").Parse(directory.Format)
+		if err != nil {
+			log.Printf("parsing room format failed: %v", err)
+		} else {
+			roomFormat = format
+		}
+	}
+
+	rooms = getRoomIndex(directory.Rooms)
+}
+
+func getRoomIndex(roomList []Room) (index map[string]Room) {
+	index = make(map[string]Room)
+	for _, room := range roomList {
+		index[room.Name] = room
+	}
+	return index
+}
+
+func GetRooms() (roomList []Room) {
+	roomList = []Room{}
+	for _, room := range rooms {
+		roomList = append(roomList, room)
+	}
+	return roomList
+}
+
+func getRoom(name string) Room {
+	room, ok := rooms[name]
+	if !ok {
+		room = Room{Name: name}
+	}
+	if room.Alias == "" {
+		room.Alias = room.Name
+	}
+	if room.LongName == "" {
+		room.LongName = room.Name
+	}
+	return room
+}
+
+// format a WebUntis room name as configured, unknown rooms are shown as they are
+func formatLocation(name string) string {
+	if name == "" {
+		return ""
+	}
+	if _, ok := rooms[name]; !ok {
+		return name
+	}
+	location := executeTemplate(roomFormat, getRoom(name))
+	if location == "" {
+		return name
+	}
+	return location
+}
+
+func formatLocations(names []string) string {
+	locations := []string{}
+	for _, name := range names {
+		if location := formatLocation(name); location != "" {
+			locations = append(locations, location)
+		}
+	}
+	return strings.Join(locations, ", ")
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"log"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/mcg-dallgow/mcg-display/services/webuntis"
+)
+
+// default prefetch settings, can be overwritten in .env
+const defaultSchoolDayInterval time.Duration = 20 * time.Minute
+const defaultOffDayInterval time.Duration = 50 * time.Minute
+const defaultJitter time.Duration = 3 * time.Minute
+const defaultQuietStart int = 22
+const defaultQuietEnd int = 6
+
+// prefetches never follow each other more quickly than this
+const minPrefetchDelay time.Duration = time.Minute
+const cacheCleanupInterval time.Duration = 24 * time.Hour
+
+type Scheduler struct {
+	SchoolDayInterval time.Duration
+	OffDayInterval    time.Duration
+	Jitter            time.Duration
+	QuietStart        int
+	QuietEnd          int
+	Teachers          []string
+	Students          []string
+	Classes           []string
+}
+
+// start prefetching events into the cache in the background
+func StartScheduler() {
+	scheduler := GetSchedulerConfig()
+	go scheduler.run()
+}
+
+func GetSchedulerConfig() (scheduler Scheduler) {
+	godotenv.Load()
+
+	scheduler = Scheduler{
+		SchoolDayInterval: getEnvDuration("PREFETCH_INTERVAL", defaultSchoolDayInterval),
+		OffDayInterval:    getEnvDuration("PREFETCH_INTERVAL_OFFDAY", defaultOffDayInterval),
+		Jitter:            getEnvDuration("PREFETCH_JITTER", defaultJitter),
+		QuietStart:        defaultQuietStart,
+		QuietEnd:          defaultQuietEnd,
+		Teachers:          getEnvList("PREFETCH_TEACHERS"),
+		Students:          getEnvList("PREFETCH_STUDENTS"),
+		Classes:           getEnvList("PREFETCH_CLASSES"),
+	}
+
+	// the jitter must stay below half the interval, otherwise delays could become negative
+	if maxJitter := min(scheduler.SchoolDayInterval, scheduler.OffDayInterval) / 2; scheduler.Jitter >= maxJitter {
+		scheduler.Jitter = maxJitter - time.Second
+	}
+
+	// quiet hours are given as a range of hours, e.g. "22-6"
+	quietHours := strings.Split(os.Getenv("PREFETCH_QUIET_HOURS"), "-")
+	if len(quietHours) == 2 {
+		quietStart, errStart := strconv.Atoi(strings.TrimSpace(quietHours[0]))
+		quietEnd, errEnd := strconv.Atoi(strings.TrimSpace(quietHours[1]))
+		if errStart == nil && errEnd == nil {
+			scheduler.QuietStart = quietStart
+			scheduler.QuietEnd = quietEnd
+		}
+	}
+
+	return scheduler
+}
+
+func (scheduler *Scheduler) run() {
+	var lastCleanup time.Time
+	for {
+		if !scheduler.isQuiet(time.Now()) {
+			scheduler.Prefetch()
+		}
+		if time.Since(lastCleanup) > cacheCleanupInterval {
+			if err := CleanupCache(); err != nil {
+				log.Printf("cache cleanup failed: %v", err)
+			}
+			lastCleanup = time.Now()
+		}
+		time.Sleep(scheduler.nextDelay(time.Now()))
+	}
+}
+
+// prefetch the default events and all configured personal and class views
+func (scheduler *Scheduler) Prefetch() {
+	start, end, _ := ParseDateRange("", "", "")
+
+	// the default events cover the search horizon, so searches are served from the cache
+	defaultEnd := end
+	if _, searchEnd, _ := ParseDateRange("", "", strconv.Itoa(GetSearchHorizon())); searchEnd.After(end) {
+		defaultEnd = searchEnd
+	}
+	if err := PrefetchEvents(start, defaultEnd, nil); err != nil {
+		log.Printf("prefetch of default events failed: %v", err)
+	}
+
+	resources := []Resource{}
+	for _, teacher := range scheduler.Teachers {
+		resources = append(resources, Resource{Type: webuntis.TypeTeacher, Name: teacher})
+	}
+	for _, student := range scheduler.Students {
+		resources = append(resources, Resource{Type: webuntis.TypeStudent, Name: student})
+	}
+	for _, class := range scheduler.Classes {
+		resources = append(resources, Resource{Type: webuntis.TypeClass, Name: class})
+	}
+	for _, resource := range resources {
+		if err := PrefetchEvents(start, end, []Resource{resource}); err != nil {
+			log.Printf("prefetch of %s %s failed: %v", resource.Type, resource.Name, err)
+		}
+	}
+}
+
+// check if the given time lies within the quiet hours at night
+func (scheduler *Scheduler) isQuiet(now time.Time) bool {
+	hour := now.Hour()
+	if scheduler.QuietStart == scheduler.QuietEnd {
+		return false
+	}
+	if scheduler.QuietStart < scheduler.QuietEnd {
+		return hour >= scheduler.QuietStart && hour < scheduler.QuietEnd
+	}
+	// quiet hours span midnight
+	return hour >= scheduler.QuietStart || hour < scheduler.QuietEnd
+}
+
+// get the time until the next prefetch, refreshing faster on school days
+func (scheduler *Scheduler) nextDelay(now time.Time) time.Duration {
+	delay := scheduler.OffDayInterval
+	if weekday := now.Weekday(); weekday != time.Saturday && weekday != time.Sunday {
+		delay = scheduler.SchoolDayInterval
+	}
+	if scheduler.Jitter > 0 {
+		delay += rand.N(2*scheduler.Jitter) - scheduler.Jitter
+	}
+	delay = max(delay, minPrefetchDelay)
+
+	// skip the quiet hours entirely instead of waking up repeatedly during the night
+	next := now.Add(delay)
+	if scheduler.isQuiet(next) {
+		quietEnd := time.Date(next.Year(), next.Month(), next.Day(), scheduler.QuietEnd, 0, 0, 0, next.Location())
+		if quietEnd.Before(next) {
+			quietEnd = quietEnd.Add(24 * time.Hour)
+		}
+		delay = quietEnd.Sub(now)
+		if scheduler.Jitter > 0 {
+			delay += rand.N(scheduler.Jitter)
+		}
+	}
+
+	return delay
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	duration, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || duration <= 0 {
+		return fallback
+	}
+	return duration
+}
+
+func getEnvList(key string) (list []string) {
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
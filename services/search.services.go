@@ -0,0 +1,112 @@
+package services
+
+import (
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/joho/godotenv"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+const defaultSearchHorizon int = 120
+const maxSearchHorizon int = 366
+
+// words of questions like "Wann ist der Tag der offenen Tür?" that do not need to match
+var searchStopWords = []string{
+	"wann", "wo", "was", "wer", "wie", "welche", "welcher", "welches", "ist", "sind", "findet", "statt", "gibt", "es",
+	"der", "die", "das", "den", "dem", "des", "ein", "eine", "einen", "und", "oder", "am", "im", "in", "an", "zum", "zur",
+}
+
+type SearchResult struct {
+	Date   string  `json:"date"`
+	Events []Event `json:"events"`
+}
+
+// get the number of days searched at most, the scheduler keeps them cached
+func GetSearchHorizon() int {
+	godotenv.Load()
+	if days, err := strconv.Atoi(os.Getenv("SEARCH_DAYS")); err == nil && days > 0 {
+		return min(days, maxSearchHorizon)
+	}
+	return defaultSearchHorizon
+}
+
+// shorten a requested range to the search horizon, as longer ranges would fetch months of uncached events
+func limitDateRange(start, end time.Time) time.Time {
+	if limit := start.AddDate(0, 0, GetSearchHorizon()-1); end.After(limit) {
+		return limit
+	}
+	return end
+}
+
+// search events by title, description, location, teachers and classes, grouped by date
+func SearchEvents(query string, start, end time.Time, filter EventFilter) (results []SearchResult, err error) {
+	results = []SearchResult{}
+	words := getSearchWords(query)
+	if len(words) == 0 {
+		return results, nil
+	}
+
+	end = limitDateRange(start, end)
+	events, err := GetEvents(start, end, nil, filter)
+	if err != nil {
+		return results, err
+	}
+
+	dates := []string{}
+	for date := range events {
+		dates = append(dates, date)
+	}
+	slices.Sort(dates)
+
+	for _, date := range dates {
+		result := SearchResult{Date: date, Events: []Event{}}
+		for _, event := range events[date] {
+			if matchesSearch(event, words) {
+				result.Events = append(result.Events, event)
+			}
+		}
+		if len(result.Events) > 0 {
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// split a query into normalized words, ignoring question words and punctuation
+func getSearchWords(query string) (words []string) {
+	tokens := strings.FieldsFunc(Normalize(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, token := range tokens {
+		if !slices.Contains(searchStopWords, token) {
+			words = append(words, token)
+		}
+	}
+	// a query only consisting of stop words is searched as it is
+	if len(words) == 0 {
+		words = tokens
+	}
+	return words
+}
+
+// check if every search word appears in one of the searchable fields
+func matchesSearch(event Event, words []string) bool {
+	fields := []string{event.Title, event.Description, event.Location, event.Category.String(), event.Subject, event.Course}
+	fields = append(fields, event.Classes...)
+	fields = append(fields, event.Teachers...)
+	fields = append(fields, getTeacherNames(event.Teachers, "")...)
+	fields = append(fields, event.Rooms...)
+	text := Normalize(strings.Join(fields, " "))
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/mcg-dallgow/mcg-display/services/webuntis"
+)
+
+// WebUntis session that only logs in once data actually has to be fetched
+type lazySession struct {
+	mutex   sync.Mutex
+	session *webuntis.Session
+}
+
+func (lazy *lazySession) get() (session *webuntis.Session, err error) {
+	lazy.mutex.Lock()
+	defer lazy.mutex.Unlock()
+
+	if lazy.session != nil {
+		return lazy.session, nil
+	}
+	username, password, err := GetCredentials()
+	if err != nil {
+		return session, err
+	}
+	loggedIn, err := webuntis.LoginPassword(username, password)
+	if err != nil {
+		return session, err
+	}
+	lazy.session = &loggedIn
+	return lazy.session, nil
+}
+
+// log out if a session was opened
+func (lazy *lazySession) logout() {
+	lazy.mutex.Lock()
+	defer lazy.mutex.Unlock()
+
+	if lazy.session != nil {
+		lazy.session.Logout()
+		lazy.session = nil
+	}
+}
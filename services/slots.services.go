@@ -0,0 +1,261 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/mcg-dallgow/mcg-display/services/webuntis"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+// lesson block exams can be written in
+type Period struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+
+	start time.Duration
+	end   time.Duration
+}
+
+// periods and number of proposed slots, configured in config/slots.json
+type SlotConfig struct {
+	Periods []Period `json:"periods"`
+	Limit   int      `json:"limit"`
+}
+
+// proposed time for a new exam
+type Slot struct {
+	Date  string    `json:"date"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// lower scores are better
+	Score float64  `json:"score"`
+	Notes []string `json:"notes"`
+}
+
+var slotConfig = getDefaultSlotConfig()
+
+// load the periods at start-up, falling back to the default periods
+func InitSlots() {
+	config := getDefaultSlotConfig()
+	err := loadConfig("slots", &config)
+	if err == nil {
+		err = config.compile()
+	}
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("loading slot config failed: %v", err)
+		}
+		return
+	}
+	slotConfig = config
+}
+
+func getDefaultSlotConfig() (config SlotConfig) {
+	config = SlotConfig{
+		Periods: []Period{
+			{Start: "07:45", End: "09:15"},
+			{Start: "09:35", End: "11:05"},
+			{Start: "11:25", End: "12:55"},
+			{Start: "13:40", End: "15:10"},
+		},
+		Limit: 10,
+	}
+	config.compile()
+	return config
+}
+
+func (config *SlotConfig) compile() (err error) {
+	for i := range config.Periods {
+		period := &config.Periods[i]
+		start, errStart := time.Parse("15:04", period.Start)
+		end, errEnd := time.Parse("15:04", period.End)
+		if errStart != nil || errEnd != nil || !end.After(start) {
+			return errors.New("error: invalid period " + period.Start + "-" + period.End)
+		}
+		period.start = time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute
+		period.end = time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute
+	}
+	if config.Limit <= 0 {
+		config.Limit = getDefaultSlotConfig().Limit
+	}
+	return nil
+}
+
+// propose free slots for an exam of a class or course, ranked by the workload they add
+func FindExamSlots(group string, duration time.Duration, start, end time.Time) (slots []Slot, err error) {
+	if group == "" {
+		return slots, errors.New("error: no class or course given")
+	}
+	if duration <= 0 {
+		return slots, errors.New("error: duration must be positive")
+	}
+	end = limitDateRange(start, end)
+
+	session := &lazySession{}
+	defer session.logout()
+	updateTeacherDirectory(session)
+
+	// exams of whole weeks are needed to check weekly workload rules
+	exams, err := getExams(session, getWeekStart(start), getWeekStart(end).AddDate(0, 0, 6), false)
+	if err != nil {
+		return slots, err
+	}
+	calendarEvents, err := getCalendarEvents(session, start, end, false)
+	if err != nil {
+		return slots, err
+	}
+	events := append(slices.Clone(exams), calendarEvents...)
+	// courses have no timetable of their own
+	if _, err := getPerson(session, group, webuntis.TypeClass); err == nil {
+		classEvents, err := getIndividualEvents(session, group, webuntis.TypeClass, start, end, false)
+		if err != nil {
+			return slots, err
+		}
+		events = append(events, classEvents...)
+	}
+	holidays, err := getHolidays(session)
+	if err != nil {
+		return slots, err
+	}
+
+	groupExams := slices.DeleteFunc(slices.Clone(exams), func(exam Event) bool {
+		return !isGroupEvent(exam, group)
+	})
+
+	slots = []Slot{}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday || isHoliday(holidays, day) {
+			continue
+		}
+		for _, slot := range getSlotCandidates(day, duration) {
+			if isSlotBlocked(slot, events, group) {
+				continue
+			}
+			if rateSlot(&slot, events, groupExams) {
+				slots = append(slots, slot)
+			}
+		}
+	}
+
+	slices.SortStableFunc(slots, func(a, b Slot) int {
+		if a.Score < b.Score {
+			return -1
+		} else if a.Score > b.Score {
+			return 1
+		}
+		return a.Start.Compare(b.Start)
+	})
+	if len(slots) > slotConfig.Limit {
+		slots = slots[:slotConfig.Limit]
+	}
+	return slots, nil
+}
+
+// get every slot of the duration starting with a period, spanning following periods if necessary
+func getSlotCandidates(day time.Time, duration time.Duration) (slots []Slot) {
+	periods := slotConfig.Periods
+	for i, period := range periods {
+		for _, lastPeriod := range periods[i:] {
+			if lastPeriod.end-period.start >= duration {
+				slots = append(slots, Slot{
+					Date:  day.Format("2006-01-02"),
+					Start: day.Add(period.start),
+					End:   day.Add(period.start + duration),
+					Notes: []string{},
+				})
+				break
+			}
+		}
+	}
+	return slots
+}
+
+// slots are blocked by events of the class or course at the same time, e.g. class trips
+func isSlotBlocked(slot Slot, events []Event, group string) bool {
+	for _, event := range events {
+		if event.Date != slot.Date || event.Status == EventCancelled || !isGroupEvent(event, group) {
+			continue
+		}
+		if event.FullDay || (event.Start.Before(slot.End) && slot.Start.Before(event.End)) {
+			return true
+		}
+	}
+	return false
+}
+
+// rate a slot by the workload it adds, slots violating a workload rule are rejected
+func rateSlot(slot *Slot, events, groupExams []Event) bool {
+	day, _ := time.Parse("2006-01-02", slot.Date)
+
+	for _, rule := range workloadRules {
+		periodStart, periodEnd := rule.getPeriod(day)
+		count := 0
+		for _, exam := range groupExams {
+			if examDay := parseEventDate(exam); rule.counts(exam) && !examDay.Before(periodStart) && !examDay.After(periodEnd) {
+				count++
+			}
+		}
+		if count+1 > rule.Max {
+			return false
+		}
+		if rule.Max > 0 {
+			slot.Score += float64(count) / float64(rule.Max)
+		}
+		if count+1 == rule.Max && rule.Max > 1 {
+			slot.Notes = append(slot.Notes, "Grenze erreicht: "+rule.Name)
+		}
+	}
+
+	// exams on consecutive days and school-wide events are possible, but not preferred
+	for _, exam := range groupExams {
+		if examDay := parseEventDate(exam); examDay.Equal(day.AddDate(0, 0, -1)) || examDay.Equal(day.AddDate(0, 0, 1)) {
+			slot.Score += 0.5
+			slot.Notes = append(slot.Notes, fmt.Sprintf("Prüfung am %s: %s", examDay.Format("02.01."), exam.Title))
+		}
+	}
+	for _, event := range events {
+		if event.Date == slot.Date && event.FullDay && slices.Contains(event.Sources, CalendarSource) && len(getMentionedClasses(event)) == 0 {
+			slot.Score += 0.5
+			slot.Notes = append(slot.Notes, "Termin am selben Tag: "+event.Title)
+		}
+	}
+	return true
+}
+
+// check if an event concerns the class or course, course codes are entered in any case
+func isGroupEvent(event Event, group string) bool {
+	if event.Course != "" && Normalize(event.Course) == Normalize(group) {
+		return true
+	}
+	return len(getCommonClasses(getMentionedClasses(event), []string{Normalize(group)})) > 0
+}
+
+func isHoliday(holidays []webuntis.Holiday, day time.Time) bool {
+	return slices.ContainsFunc(holidays, func(holiday webuntis.Holiday) bool {
+		return !day.Before(holiday.Start) && !day.After(holiday.End)
+	})
+}
+
+func getHolidays(session *lazySession) (holidays []webuntis.Holiday, err error) {
+	err = loadCached(masterDataCache, "holidays", &holidays)
+	if err == nil {
+		return holidays, nil
+	}
+
+	untis, err := session.get()
+	if err != nil {
+		return holidays, err
+	}
+	holidays, err = untis.GetHolidays()
+	if err != nil {
+		return holidays, err
+	}
+	writeCached(masterDataCache, "holidays", holidays)
+
+	return holidays, nil
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"testing"
+
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+func TestIsGroupEvent(t *testing.T) {
+	tests := []struct {
+		event Event
+		group string
+		want  bool
+	}{
+		{event: Event{Course: "ma-lk1", Classes: []string{"Jhg 11"}}, group: "MA-LK1", want: true},
+		{event: Event{Course: "ma-lk1", Classes: []string{"Jhg 11"}}, group: "en-gk2", want: false},
+		{event: Event{Classes: []string{"7a"}}, group: "7A", want: true},
+		{event: Event{Title: "Wandertag 7a"}, group: "7a", want: true},
+		{event: Event{Classes: []string{"7b"}}, group: "7a", want: false},
+	}
+
+	for _, test := range tests {
+		if got := isGroupEvent(test.event, test.group); got != test.want {
+			t.Errorf("isGroupEvent(%+v, %q) = %v, want %v", test.event, test.group, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mcg-dallgow/mcg-display/services/webuntis"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+const busiestDaysCount int = 10
+
+// labelled value of a statistic
+type StatEntry struct {
+	Label string  `json:"label"`
+	Value float64 `json:"value"`
+}
+
+type MonthStats struct {
+	Month      string      `json:"month"`
+	Categories []StatEntry `json:"categories"`
+}
+
+type Statistics struct {
+	Start             string       `json:"start"`
+	End               string       `json:"end"`
+	ExamsPerClass     []StatEntry  `json:"examsPerClass"`
+	ExamsPerSubject   []StatEntry  `json:"examsPerSubject"`
+	ExamsPerWeek      []StatEntry  `json:"examsPerWeek"`
+	EventsPerMonth    []MonthStats `json:"eventsPerMonth"`
+	InvigilationHours []StatEntry  `json:"invigilationHours"`
+	BusiestDays       []StatEntry  `json:"busiestDays"`
+}
+
+// aggregate the events and exams within the date range
+func GetStatistics(start, end time.Time) (stats Statistics, err error) {
+	end = limitDateRange(start, end)
+	events, err := GetEvents(start, end, nil, EventFilter{Audience: StaffAudience})
+	if err != nil {
+		return stats, err
+	}
+	exams, err := getInvigilations(start, end)
+	if err != nil {
+		return stats, err
+	}
+
+	eventList := []Event{}
+	for day := start; !day.After(end); day = day.Add(24 * time.Hour) {
+		eventList = append(eventList, events[day.Format("2006-01-02")]...)
+	}
+
+	stats = Statistics{
+		Start:             start.Format("2006-01-02"),
+		End:               end.Format("2006-01-02"),
+		ExamsPerClass:     []StatEntry{},
+		ExamsPerSubject:   []StatEntry{},
+		ExamsPerWeek:      []StatEntry{},
+		EventsPerMonth:    []MonthStats{},
+		InvigilationHours: []StatEntry{},
+		BusiestDays:       []StatEntry{},
+	}
+
+	// events spanning several days are counted once per exam, week, month and day
+	counted := make(map[string]bool)
+	isFirst := func(key string, event Event) bool {
+		if event.Id == "" {
+			return true
+		}
+		key += "/" + event.Id
+		first := !counted[key]
+		counted[key] = true
+		return first
+	}
+	for _, event := range eventList {
+		if slices.Contains(event.Sources, ExamSource) && event.Status != EventCancelled {
+			if isFirst("exam", event) {
+				for _, class := range event.Classes {
+					stats.ExamsPerClass = addStat(stats.ExamsPerClass, class, 1)
+				}
+				if event.Subject != "" {
+					stats.ExamsPerSubject = addStat(stats.ExamsPerSubject, event.Subject, 1)
+				}
+			}
+			year, week := parseEventDate(event).ISOWeek()
+			if label := fmt.Sprintf("%d-W%02d", year, week); isFirst(label, event) {
+				stats.ExamsPerWeek = addStat(stats.ExamsPerWeek, label, 1)
+			}
+		}
+
+		month := parseEventDate(event).Format("2006-01")
+		if isFirst(month, event) {
+			index := slices.IndexFunc(stats.EventsPerMonth, func(monthStats MonthStats) bool {
+				return monthStats.Month == month
+			})
+			if index == -1 {
+				stats.EventsPerMonth = append(stats.EventsPerMonth, MonthStats{Month: month, Categories: []StatEntry{}})
+				index = len(stats.EventsPerMonth) - 1
+			}
+			stats.EventsPerMonth[index].Categories = addStat(stats.EventsPerMonth[index].Categories, event.Category.String(), 1)
+		}
+
+		if isFirst(event.Date, event) {
+			stats.BusiestDays = addStat(stats.BusiestDays, event.Date, 1)
+		}
+	}
+
+	for _, exam := range exams {
+		for _, invigilator := range exam.Invigilators {
+			hours := invigilator.End.Sub(invigilator.Start.Time).Hours()
+			for _, teacher := range invigilator.Teachers {
+				stats.InvigilationHours = addStat(stats.InvigilationHours, teacher.ShortName, hours)
+			}
+		}
+	}
+
+	sortStatsByLabel(stats.ExamsPerClass)
+	sortStatsByValue(stats.ExamsPerSubject)
+	sortStatsByLabel(stats.ExamsPerWeek)
+	slices.SortFunc(stats.EventsPerMonth, func(a, b MonthStats) int {
+		return strings.Compare(a.Month, b.Month)
+	})
+	sortStatsByValue(stats.InvigilationHours)
+	sortStatsByValue(stats.BusiestDays)
+	if len(stats.BusiestDays) > busiestDaysCount {
+		stats.BusiestDays = stats.BusiestDays[:busiestDaysCount]
+	}
+
+	return stats, nil
+}
+
+// invigilators are only contained in the exams as returned by WebUntis
+func getInvigilations(start, end time.Time) (exams []webuntis.Exam, err error) {
+	session := &lazySession{}
+	defer session.logout()
+
+	return getRawExams(session, start, end)
+}
+
+func addStat(entries []StatEntry, label string, value float64) []StatEntry {
+	for i := range entries {
+		if entries[i].Label == label {
+			entries[i].Value += value
+			return entries
+		}
+	}
+	return append(entries, StatEntry{Label: label, Value: value})
+}
+
+func sortStatsByLabel(entries []StatEntry) {
+	slices.SortFunc(entries, func(a, b StatEntry) int {
+		return strings.Compare(a.Label, b.Label)
+	})
+}
+
+// sort the entries from the highest to the lowest value
+func sortStatsByValue(entries []StatEntry) {
+	slices.SortStableFunc(entries, func(a, b StatEntry) int {
+		if a.Value > b.Value {
+			return -1
+		} else if a.Value < b.Value {
+			return 1
+		}
+		return strings.Compare(a.Label, b.Label)
+	})
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+// catalogue used if there is no valid config/subjects.json
+var defaultSubjects = []Subject{
+	{Name: "Biologie", Codes: []string{"BI"}, Abbreviations: []string{"Bio"}, Color: "#65A30D"},
+	{Name: "Chemie", Codes: []string{"CH"}, Abbreviations: []string{"Che"}, Color: "#0D9488"},
+	{Name: "Deutsch", Codes: []string{"DE"}, Abbreviations: []string{"Deu"}, Color: "#DC2626"},
+	{Name: "Englisch", Codes: []string{"EN"}, Abbreviations: []string{"Eng"}, Color: "#2563EB"},
+	{Name: "Französisch", Codes: []string{"FR"}, Abbreviations: []string{"Fra"}, Color: "#7C3AED"},
+	{Name: "Geographie", Codes: []string{"EK"}, Abbreviations: []string{"Geo"}, Synonyms: []string{"Erdkunde"}, Color: "#CA8A04"},
+	{Name: "Geschichte", Codes: []string{"GE"}, Abbreviations: []string{"Ges"}, Color: "#92400E"},
+	{Name: "Informatik", Codes: []string{"IF"}, Abbreviations: []string{"Inf"}, Color: "#475569"},
+	{Name: "Kunst", Codes: []string{"KU"}, Abbreviations: []string{"Kun"}, Color: "#DB2777"},
+	{Name: "Latein", Codes: []string{"LA"}, Abbreviations: []string{"Lat"}, Color: "#9F1239"},
+	{Name: "LER", Codes: []string{"LER", "LE"}, Synonyms: []string{"Lebensgestaltung-Ethik-Religionskunde"}, Color: "#EA580C"},
+	{Name: "Mathematik", Codes: []string{"MA"}, Abbreviations: []string{"Mat"}, Synonyms: []string{"Mathe"}, Color: "#1D4ED8"},
+	{Name: "Musik", Codes: []string{"MU"}, Abbreviations: []string{"Mus"}, Color: "#C026D3"},
+	{Name: "PB", Codes: []string{"PB"}, Synonyms: []string{"Politische Bildung", "Polit. Bildung"}, Color: "#B45309"},
+	{Name: "Physik", Codes: []string{"PH"}, Abbreviations: []string{"Phy"}, Color: "#0369A1"},
+	{Name: "Recht", Codes: []string{"RL"}, Abbreviations: []string{"Rec"}, Color: "#57534E"},
+	{Name: "ev. Religion", Codes: []string{"RE"}, Abbreviations: []string{"evR"}, Color: "#A16207"},
+	{Name: "kat. Religion", Codes: []string{"RK"}, Abbreviations: []string{"kaR"}, Color: "#A16207"},
+	{Name: "Seminarkurs", Codes: []string{"SK"}, Color: "#4B5563"},
+	{Name: "Spanisch", Codes: []string{"SN"}, Abbreviations: []string{"Spa"}, Color: "#E11D48"},
+	{Name: "Sport", Codes: []string{"SP"}, Abbreviations: []string{"Spo"}, Color: "#16A34A"},
+	{Name: "Technik", Codes: []string{"TE"}, Abbreviations: []string{"Tec"}, Color: "#52525B"},
+	{Name: "WAT", Codes: []string{"WAT", "WA"}, Synonyms: []string{"Wirtschaft-Arbeit-Technik"}, Color: "#0F766E"},
+}
+
+var subjects = defaultSubjects
+
+// load the subject catalogue from config/subjects.json
+func InitSubjects() {
+	var catalogue struct {
+		Subjects []Subject `json:"subjects"`
+	}
+	err := loadConfig("subjects", &catalogue)
+	if err == nil {
+		err = validateSubjects(catalogue.Subjects)
+	}
+	if err != nil {
+		log.Printf("loading subject catalogue failed: %v", err)
+		return
+	}
+	subjects = catalogue.Subjects
+}
+
+func GetSubjects() []Subject {
+	return subjects
+}
+
+// check that every subject has a name and no WebUntis code is used twice
+func validateSubjects(subjects []Subject) (err error) {
+	codes := make(map[string]string)
+	for _, subject := range subjects {
+		if subject.Name == "" {
+			return errors.New("error: subject without name in catalogue")
+		}
+		for _, code := range subject.Codes {
+			code = strings.ToUpper(code)
+			if other, ok := codes[code]; ok {
+				return errors.New("error: code " + code + " is used by both " + other + " and " + subject.Name)
+			}
+			codes[code] = subject.Name
+		}
+	}
+	return nil
+}
+
+// get the subject with the longest code the WebUntis short name starts with, ignoring case
+func getSubjectByCode(shortName string) (subject Subject, ok bool) {
+	shortName = strings.ToUpper(shortName)
+	length := 0
+	for _, currSubject := range subjects {
+		for _, code := range currSubject.Codes {
+			if len(code) > length && strings.HasPrefix(shortName, strings.ToUpper(code)) {
+				subject = currSubject
+				length = len(code)
+				ok = true
+			}
+		}
+	}
+	return subject, ok
+}
+
+// get the first subject mentioned in any of the texts
+func getSubjectFromTexts(texts []string) (subject Subject, ok bool) {
+	for _, currSubject := range subjects {
+		if AnyContainAny(texts, currSubject.Variants(), false) {
+			return currSubject, true
+		}
+	}
+	return subject, false
+}
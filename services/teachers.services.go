@@ -0,0 +1,185 @@
+package services
+
+import (
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mcg-dallgow/mcg-display/services/webuntis"
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+const defaultMaxTeacherNames int = 2
+
+type TeacherConfig struct {
+	Format TeacherFormat `json:"format"`
+	// more teachers are compressed, e.g. "Müller, Schmidt +2 weitere"
+	MaxNames int       `json:"maxNames"`
+	Teachers []Teacher `json:"teachers"`
+}
+
+var teacherConfig = TeacherConfig{Format: FullNameFormat, MaxNames: defaultMaxTeacherNames}
+
+// teachers from WebUntis master data, indexed by short and long name
+var teacherDirectory = map[string]Teacher{}
+var teacherDirectoryUpdated time.Time
+var teacherMutex sync.RWMutex
+
+// load the teacher display settings and overrides at start-up
+func InitTeachers() {
+	config := TeacherConfig{Format: FullNameFormat, MaxNames: defaultMaxTeacherNames}
+	err := loadConfig("teachers", &config)
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("loading teacher config failed: %v", err)
+		return
+	}
+
+	teacherMutex.Lock()
+	teacherConfig = config
+	teacherDirectory = buildTeacherDirectory(nil)
+	teacherDirectoryUpdated = time.Time{}
+	teacherMutex.Unlock()
+}
+
+func GetTeachers() (teachers []Teacher) {
+	teacherMutex.RLock()
+	defer teacherMutex.RUnlock()
+
+	teachers = []Teacher{}
+	for key, teacher := range teacherDirectory {
+		// every teacher is indexed by short name and by long name
+		if key == teacher.ShortName || teacher.ShortName == "" {
+			teachers = append(teachers, teacher)
+		}
+	}
+	return teachers
+}
+
+// rebuild the teacher directory from the (cached) WebUntis master data once it expired
+func updateTeacherDirectory(session *lazySession) {
+	teacherMutex.RLock()
+	updated := teacherDirectoryUpdated
+	teacherMutex.RUnlock()
+	if time.Since(updated) < cacheTTLs[masterDataCache] {
+		return
+	}
+
+	persons, err := getPersons(session, webuntis.TypeTeacher)
+	if err != nil {
+		return
+	}
+
+	teacherMutex.Lock()
+	teacherDirectory = buildTeacherDirectory(persons)
+	teacherDirectoryUpdated = time.Now()
+	teacherMutex.Unlock()
+}
+
+func buildTeacherDirectory(persons []webuntis.UntisValue) (directory map[string]Teacher) {
+	directory = map[string]Teacher{}
+	for _, person := range persons {
+		teacher := Teacher{ShortName: person.ShortName, LongName: person.LongName}
+		directory[teacher.ShortName] = teacher
+		directory[teacher.LongName] = teacher
+	}
+
+	for _, override := range teacherConfig.Teachers {
+		teacher := directory[override.ShortName]
+		teacher.ShortName = override.ShortName
+		if override.LongName != "" {
+			teacher.LongName = override.LongName
+		}
+		teacher.DisplayName = override.DisplayName
+		teacher.Salutation = override.Salutation
+		teacher.Format = override.Format
+
+		directory[teacher.ShortName] = teacher
+		if teacher.LongName != "" {
+			directory[teacher.LongName] = teacher
+		}
+	}
+	return directory
+}
+
+// look up a teacher by short or long name, unknown teachers keep the given name
+func getTeacher(name string) Teacher {
+	teacherMutex.RLock()
+	defer teacherMutex.RUnlock()
+
+	teacher, ok := teacherDirectory[name]
+	if !ok {
+		return Teacher{LongName: name}
+	}
+	return teacher
+}
+
+func hasTeacher(name string) bool {
+	teacherMutex.RLock()
+	defer teacherMutex.RUnlock()
+
+	_, ok := teacherDirectory[name]
+	return ok
+}
+
+// check if the teacher, given by short or long name, is one of the event's teachers
+func hasEventTeacher(event Event, name string) bool {
+	shortName := getTeacher(name).ShortName
+	if shortName == "" {
+		shortName = name
+	}
+	return slices.ContainsFunc(event.Teachers, func(teacher string) bool {
+		return teacher == shortName || getTeacher(teacher).ShortName == shortName
+	})
+}
+
+// get the display names of teachers in the given format, or the configured one if empty
+func getTeacherNames(names []string, format TeacherFormat) (teacherNames []string) {
+	if format == "" {
+		teacherMutex.RLock()
+		format = teacherConfig.Format
+		teacherMutex.RUnlock()
+	}
+
+	for _, name := range names {
+		if teacherName := getTeacher(name).Name(format); teacherName != "" {
+			teacherNames = append(teacherNames, teacherName)
+		}
+	}
+	return teacherNames
+}
+
+// format teachers for titles, compressing long lists instead of dropping them
+func formatTeachers(names []string, format TeacherFormat) string {
+	teacherNames := getTeacherNames(names, format)
+
+	teacherMutex.RLock()
+	maxNames := teacherConfig.MaxNames
+	teacherMutex.RUnlock()
+
+	if maxNames > 0 && len(teacherNames) > maxNames {
+		return strings.Join(teacherNames[:maxNames], ", ") + " +" + strconv.Itoa(len(teacherNames)-maxNames) + " weitere"
+	}
+	return strings.Join(teacherNames, ", ")
+}
+
+// show the teachers in titles in the display's format, titles are formatted when events are fetched
+func formatEventTeachers(events []Event, format TeacherFormat) []Event {
+	if format == "" {
+		return events
+	}
+	for i, event := range events {
+		if configured := formatTeachers(event.Teachers, ""); configured != "" {
+			title := strings.Replace(event.Title, configured, formatTeachers(event.Teachers, format), 1)
+			events[i].Title = strings.Join(strings.Fields(title), " ")
+		}
+		// hidden teachers must not be published through the structured fields either
+		if format == HiddenFormat {
+			events[i].Teachers = nil
+		}
+	}
+	return events
+}
@@ -116,3 +116,9 @@ func AnyContainAny(strs []string, subs []string, ignoreCase bool) bool {
 	}
 	return false
 }
+
+// normalize German text for comparisons, e.g. "Tür" and "TUER" become "tuer"
+func Normalize(text string) string {
+	replacer := strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss")
+	return replacer.Replace(strings.ToLower(text))
+}
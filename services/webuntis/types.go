@@ -49,6 +49,14 @@ type CalendarEvent struct {
 	Color    string
 }
 
+type Holiday struct {
+	Id       int
+	Name     string
+	LongName string
+	Start    time.Time
+	End      time.Time
+}
+
 type UntisValue struct {
 	Id          int    `json:"id"`
 	ShortName   string `json:"shortName"`
@@ -86,4 +94,13 @@ type PersonType string
 const (
 	TypeStudent PersonType = "student"
 	TypeTeacher PersonType = "teacher"
+	TypeClass   PersonType = "class"
 )
+
+// plural used as key in WebUntis responses
+func (personType PersonType) plural() string {
+	if personType == TypeClass {
+		return "classes"
+	}
+	return string(personType) + "s"
+}
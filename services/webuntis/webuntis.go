@@ -45,7 +45,7 @@ const (
 	logoutRequest
 )
 
-// structs used for json encoding of auth request bodies
+// structs used for json encoding of JSON-RPC request bodies
 type authRequestBody struct {
 	Id      string `json:"id"`
 	Method  string `json:"method"`
@@ -473,7 +473,46 @@ func (session *Session) GetTimetableEvents(start, end time.Time) (events []Timet
 	return events, nil
 }
 
-func (session *Session) getPersons(personType PersonType) (persons []UntisValue, err error) {
+func (session *Session) GetHolidays() (holidays []Holiday, err error) {
+	path := "WebUntis/jsonrpc.do"
+	queryParams := url.Values{"school": {schoolName}}
+	jsonBody, _ := json.Marshal(authRequestBody{
+		Id:      appId,
+		Method:  "getHolidays",
+		Params:  struct{}{},
+		JsonRpc: "2.0",
+	})
+
+	res, err := session.Request(http.MethodPost, path, queryParams, jsonBody, false)
+	if err != nil {
+		return holidays, err
+	}
+
+	var parser fastjson.Parser
+	jsonData, err := parser.Parse(res)
+	if err != nil {
+		return holidays, err
+	}
+	if jsonData.Exists("error") {
+		return holidays, errors.New("error: " + string(jsonData.GetStringBytes("error", "message")))
+	}
+
+	for _, holiday := range jsonData.GetArray("result") {
+		start, _ := time.Parse("20060102", strconv.Itoa(holiday.GetInt("startDate")))
+		end, _ := time.Parse("20060102", strconv.Itoa(holiday.GetInt("endDate")))
+		holidays = append(holidays, Holiday{
+			Id:       holiday.GetInt("id"),
+			Name:     string(holiday.GetStringBytes("name")),
+			LongName: string(holiday.GetStringBytes("longName")),
+			Start:    start,
+			End:      end,
+		})
+	}
+
+	return holidays, nil
+}
+
+func (session *Session) GetPersons(personType PersonType) (persons []UntisValue, err error) {
 	pTypeStr := string(personType)
 
 	path := "WebUntis/api/rest/view/v1/timetable/filter"
@@ -493,7 +532,7 @@ func (session *Session) getPersons(personType PersonType) (persons []UntisValue,
 		return persons, err
 	}
 
-	for _, person := range jsonData.GetArray(pTypeStr + "s") {
+	for _, person := range jsonData.GetArray(personType.plural()) {
 		persons = append(persons, UntisValue{
 			Id:          person.GetInt(pTypeStr, "id"),
 			ShortName:   string(person.GetStringBytes(pTypeStr, "shortName")),
@@ -505,23 +544,7 @@ func (session *Session) getPersons(personType PersonType) (persons []UntisValue,
 	return persons, nil
 }
 
-func (session *Session) GetIndividualEvents(person string, personType PersonType, start, end time.Time) (timetableEvents []TimetableEvent, calendarEvents []CalendarEvent, exams []Exam, err error) {
-	personExists := false
-	var personData UntisValue
-	persons, err := session.getPersons(personType)
-	if err != nil {
-		return timetableEvents, calendarEvents, exams, err
-	}
-	for _, currPerson := range persons {
-		if currPerson.DisplayName == person {
-			personExists = true
-			personData = currPerson
-		}
-	}
-	if !personExists {
-		return timetableEvents, calendarEvents, exams, errors.New("error: that " + string(personType) + " does not exist")
-	}
-
+func (session *Session) GetIndividualEvents(personData UntisValue, personType PersonType, start, end time.Time) (timetableEvents []TimetableEvent, calendarEvents []CalendarEvent, exams []Exam, err error) {
 	path := "WebUntis/api/rest/view/v1/timetable/entries"
 	queryParams := url.Values{
 		"start":        {convertDateToUntis(start)},
@@ -556,7 +579,8 @@ func (session *Session) GetIndividualEvents(person string, personType PersonType
 
 				var entryTeachers []string
 				for _, teacherData := range entry.GetArray("position2") {
-					entryTeachers = append(entryTeachers, string(teacherData.GetStringBytes("current", "longName")))
+					// short names identify teachers in all other sources
+					entryTeachers = append(entryTeachers, string(teacherData.GetStringBytes("current", "shortName")))
 				}
 
 				timetableEvents = append(timetableEvents, TimetableEvent{
@@ -596,7 +620,8 @@ func (session *Session) GetIndividualEvents(person string, personType PersonType
 	// combine exams ranging accross multiple lessons
 	combinedExams := []Exam{}
 	combined := false
-	for i, exam := range exams[:len(exams)-1] {
+	for i := 0; i < len(exams)-1; i++ {
+		exam := exams[i]
 		if exam.Name == exams[i+1].Name {
 			combinedExams = append(combinedExams, Exam{
 				Name:  exam.Name,
@@ -611,6 +636,10 @@ func (session *Session) GetIndividualEvents(person string, personType PersonType
 			combined = false
 		}
 	}
+	// the last exam is only compared with its predecessor
+	if len(exams) > 0 && !combined {
+		combinedExams = append(combinedExams, exams[len(exams)-1])
+	}
 
 	calendarEvents = parseCalendarEvents(jsonData)
 
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	. "github.com/mcg-dallgow/mcg-display/types"
+)
+
+// periods exams are counted in
+const (
+	dayPeriod  string = "day"
+	weekPeriod string = "week"
+)
+
+type WorkloadSeverity string
+
+const (
+	WorkloadViolation WorkloadSeverity = "violation"
+	WorkloadWarning   WorkloadSeverity = "warning"
+)
+
+// maximum number of exams per class or course within a day or week
+type WorkloadRule struct {
+	Name   string `json:"name"`
+	Period string `json:"period"`
+	Max    int    `json:"max"`
+	// only exams of these types are counted, all exams if empty
+	Types []string `json:"types"`
+}
+
+// class or course exceeding or reaching the maximum of a rule within a period
+type WorkloadIssue struct {
+	Rule     string           `json:"rule"`
+	Group    string           `json:"group"`
+	Start    string           `json:"start"`
+	End      string           `json:"end"`
+	Count    int              `json:"count"`
+	Max      int              `json:"max"`
+	Severity WorkloadSeverity `json:"severity"`
+	Exams    []Event          `json:"exams"`
+}
+
+// short tests do not count towards the workload
+var workloadExamTypes = []string{"KA", "Klassenarbeit", "Klausur"}
+
+// rules used if there is no config/workload.json
+var defaultWorkloadRules = []WorkloadRule{
+	{Name: "Höchstens eine Klassenarbeit oder Klausur pro Tag", Period: dayPeriod, Max: 1, Types: workloadExamTypes},
+	{Name: "Höchstens drei Klassenarbeiten oder Klausuren pro Woche", Period: weekPeriod, Max: 3, Types: workloadExamTypes},
+}
+
+var workloadRules = defaultWorkloadRules
+
+// load the workload rules at start-up
+func InitWorkloadRules() {
+	var config struct {
+		Rules []WorkloadRule `json:"rules"`
+	}
+	err := loadConfig("workload", &config)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("loading workload rules failed: %v", err)
+		}
+		return
+	}
+	for _, rule := range config.Rules {
+		if rule.Period != dayPeriod && rule.Period != weekPeriod {
+			log.Printf("loading workload rules failed: %v", errors.New("error: unknown period "+rule.Period))
+			return
+		}
+	}
+	workloadRules = config.Rules
+}
+
+func GetWorkloadRules() []WorkloadRule {
+	return workloadRules
+}
+
+// check the exams within the date range against the workload rules
+func GetExamWorkload(start, end time.Time) (issues []WorkloadIssue, err error) {
+	end = limitDateRange(start, end)
+	exams, err := getWorkloadExams(start, end)
+	if err != nil {
+		return issues, err
+	}
+
+	issues = []WorkloadIssue{}
+	for _, issue := range checkWorkload(exams, workloadRules) {
+		// weeks are checked completely, but only issues within the range are reported
+		if issue.End >= start.Format("2006-01-02") && issue.Start <= end.Format("2006-01-02") {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// get the exams of all weeks touching the date range
+func getWorkloadExams(start, end time.Time) (exams []Event, err error) {
+	session := &lazySession{}
+	defer session.logout()
+	updateTeacherDirectory(session)
+
+	return getExams(session, getWeekStart(start), getWeekStart(end).AddDate(0, 0, 6), false)
+}
+
+func checkWorkload(exams []Event, rules []WorkloadRule) (issues []WorkloadIssue) {
+	for _, rule := range rules {
+		periods := make(map[[2]string][]Event)
+		for _, exam := range exams {
+			if !rule.counts(exam) {
+				continue
+			}
+			periodStart, _ := rule.getPeriod(parseEventDate(exam))
+			for _, group := range getWorkloadGroups(exam) {
+				key := [2]string{group, periodStart.Format("2006-01-02")}
+				periods[key] = append(periods[key], exam)
+			}
+		}
+
+		for key, periodExams := range periods {
+			severity := WorkloadSeverity("")
+			if len(periodExams) > rule.Max {
+				severity = WorkloadViolation
+			} else if len(periodExams) == rule.Max && rule.Max > 1 {
+				// reaching the maximum is only worth a warning if several exams are allowed
+				severity = WorkloadWarning
+			}
+			if severity == "" {
+				continue
+			}
+
+			periodStart, periodEnd := rule.getPeriod(parseEventDate(periodExams[0]))
+			sortEvents(periodExams)
+			issues = append(issues, WorkloadIssue{
+				Rule:     rule.Name,
+				Group:    key[0],
+				Start:    periodStart.Format("2006-01-02"),
+				End:      periodEnd.Format("2006-01-02"),
+				Count:    len(periodExams),
+				Max:      rule.Max,
+				Severity: severity,
+				Exams:    periodExams,
+			})
+		}
+	}
+
+	slices.SortFunc(issues, func(a, b WorkloadIssue) int {
+		if a.Start != b.Start {
+			return strings.Compare(a.Start, b.Start)
+		}
+		if a.Group != b.Group {
+			return strings.Compare(a.Group, b.Group)
+		}
+		return strings.Compare(a.Rule, b.Rule)
+	})
+	return issues
+}
+
+func (rule *WorkloadRule) counts(exam Event) bool {
+	if exam.Status == EventCancelled {
+		return false
+	}
+	return len(rule.Types) == 0 || slices.ContainsFunc(rule.Types, func(examType string) bool {
+		return strings.EqualFold(examType, exam.ExamType)
+	})
+}
+
+// get the first and last day of the period containing the day
+func (rule *WorkloadRule) getPeriod(day time.Time) (start, end time.Time) {
+	if rule.Period == weekPeriod {
+		start = getWeekStart(day)
+		return start, start.AddDate(0, 0, 6)
+	}
+	return day, day
+}
+
+// exams are counted per class, in Sek II and without classes per course
+func getWorkloadGroups(exam Event) (groups []string) {
+	if exam.Course != "" && (isSekIIEvent(exam) || len(exam.Classes) == 0) {
+		return []string{exam.Course}
+	}
+	return exam.Classes
+}
+
+func getWeekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+func parseEventDate(event Event) (date time.Time) {
+	date, _ = time.Parse("2006-01-02", event.Date)
+	return date
+}
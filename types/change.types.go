@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+type ChangeType string
+
+const (
+	EventAdded   ChangeType = "added"
+	EventChanged ChangeType = "changed"
+	EventRemoved ChangeType = "removed"
+)
+
+func (c ChangeType) Label() string {
+	switch c {
+	case EventAdded:
+		return "neu"
+	case EventChanged:
+		return "geändert"
+	case EventRemoved:
+		return "entfällt"
+	}
+	return ""
+}
+
+type Change struct {
+	Type   ChangeType `json:"type"`
+	Time   time.Time  `json:"time"`
+	Source string     `json:"source"`
+	Fields []string   `json:"fields,omitempty"`
+	Before *Event     `json:"before,omitempty"`
+	After  *Event     `json:"after,omitempty"`
+}
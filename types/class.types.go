@@ -0,0 +1,49 @@
+package types
+
+import "strconv"
+
+// grades taught at the school
+const (
+	FirstGrade    int = 7
+	LastSekIGrade int = 10
+	LastGrade     int = 12
+)
+
+type SchoolLevel int
+
+const (
+	SekI SchoolLevel = iota
+	SekII
+)
+
+func (level SchoolLevel) String() string {
+	return []string{
+		"Sek I",
+		"Sek II",
+	}[level]
+}
+
+type Class struct {
+	Name   string `json:"name"`
+	Grade  int    `json:"grade"`
+	Suffix string `json:"suffix"`
+	// the class stands for the whole grade level, e.g. "Jhg 11"
+	GradeLevel bool `json:"gradeLevel"`
+}
+
+func (class Class) Level() SchoolLevel {
+	if class.Grade > LastSekIGrade {
+		return SekII
+	}
+	return SekI
+}
+
+func (class Class) String() string {
+	if class.Grade == 0 {
+		return class.Name
+	}
+	if class.GradeLevel {
+		return "Jg " + strconv.Itoa(class.Grade)
+	}
+	return strconv.Itoa(class.Grade) + class.Suffix
+}
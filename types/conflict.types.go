@@ -0,0 +1,30 @@
+package types
+
+type ConflictType string
+
+const (
+	ClassConflict   ConflictType = "class"
+	RoomConflict    ConflictType = "room"
+	TeacherConflict ConflictType = "teacher"
+)
+
+func (c ConflictType) Label() string {
+	switch c {
+	case ClassConflict:
+		return "Klasse"
+	case RoomConflict:
+		return "Raum"
+	case TeacherConflict:
+		return "Lehrkraft"
+	}
+	return ""
+}
+
+// overlapping events sharing a class, room or teacher
+type Conflict struct {
+	Type ConflictType `json:"type"`
+	// classes, rooms or teachers both events share
+	Resources []string `json:"resources"`
+	Date      string   `json:"date"`
+	Events    []Event  `json:"events"`
+}
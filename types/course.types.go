@@ -0,0 +1,26 @@
+package types
+
+type CourseLevel string
+
+const (
+	NoCourseLevel  CourseLevel = ""
+	BasicCourse    CourseLevel = "GK"
+	AdvancedCourse CourseLevel = "LK"
+	SeminarCourse  CourseLevel = "SK"
+)
+
+func (level CourseLevel) String() string {
+	switch level {
+	case SeminarCourse:
+		return "Seminarkurs"
+	}
+	return string(level)
+}
+
+type Course struct {
+	Code    string      `json:"code"`
+	Subject Subject     `json:"subject"`
+	Level   CourseLevel `json:"level"`
+	Number  int         `json:"number"`
+	Teacher string      `json:"teacher"`
+}
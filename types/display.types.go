@@ -0,0 +1,36 @@
+package types
+
+import "slices"
+
+type Audience string
+
+const (
+	PublicAudience  Audience = "public"
+	StudentAudience Audience = "students"
+	StaffAudience   Audience = "staff"
+)
+
+// check if events of the category may be shown to the audience
+func (audience Audience) Allows(category EventCategory) bool {
+	switch audience {
+	case PublicAudience:
+		return slices.Contains([]EventCategory{PublicEvent, AGEvent}, category)
+	case StudentAudience:
+		return category != TeacherEvent
+	}
+	// unknown audiences see nothing
+	return audience == StaffAudience
+}
+
+func (audience Audience) IsValid() bool {
+	return slices.Contains([]Audience{PublicAudience, StudentAudience, StaffAudience}, audience)
+}
+
+// settings of a display, configured in config/displays.json
+type DisplayProfile struct {
+	Audience Audience `json:"audience"`
+	Include  []string `json:"include"`
+	Exclude  []string `json:"exclude"`
+	// e.g. "hidden" to leave out teacher names on public displays
+	TeacherFormat TeacherFormat `json:"teacherFormat,omitempty"`
+}
@@ -1,18 +1,67 @@
 package types
 
-import "time"
+import (
+	"errors"
+	"strconv"
+	"time"
+)
 
 type Event struct {
-	Title       string
-	Description string
-	Category    EventCategory
-	Date        string
-	FullDay     bool
-	Start       time.Time
-	End         time.Time
-	Location    string
+	// stable identifier of the event across fetches
+	Id          string        `json:"id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Category    EventCategory `json:"category"`
+	Date        string        `json:"date"`
+	FullDay     bool          `json:"fullDay"`
+	Start       time.Time     `json:"start"`
+	End         time.Time     `json:"end"`
+	Location    string        `json:"location"`
+	Sources     []EventSource `json:"sources"`
+	Classes     []string      `json:"classes,omitempty"`
+	Teachers    []string      `json:"teachers,omitempty"`
+	Rooms       []string      `json:"rooms,omitempty"`
+	Subject     string        `json:"subject,omitempty"`
+	Course      string        `json:"course,omitempty"`
+	Status      EventStatus   `json:"status"`
+	// type of exams, e.g. "KA" or "Klausur"
+	ExamType string `json:"examType,omitempty"`
+	// time the event was last seen with different data
+	Modified time.Time `json:"modified"`
+	// day of an event spanning several days and the number of days it covers
+	Day      int `json:"day,omitempty"`
+	DayCount int `json:"dayCount,omitempty"`
+	// selected teachers, students or classes the event belongs to in combined views
+	Resources []string   `json:"resources,omitempty"`
+	Change    ChangeType `json:"change,omitempty"`
+	// kinds of conflicts with other events, only determined for staff displays
+	Conflicts []ConflictType `json:"conflicts,omitempty"`
+}
+
+type EventSource string
+
+const (
+	CalendarSource  EventSource = "calendar"
+	ExamSource      EventSource = "exams"
+	TimetableSource EventSource = "timetable"
+)
+
+func (event Event) IsMultiDay() bool {
+	return event.DayCount > 1
 }
 
+// label like "Tag 2/5" for a day of an event spanning several days
+func (event Event) DayLabel() string {
+	return "Tag " + strconv.Itoa(event.Day) + "/" + strconv.Itoa(event.DayCount)
+}
+
+type EventStatus string
+
+const (
+	EventScheduled EventStatus = "scheduled"
+	EventCancelled EventStatus = "cancelled"
+)
+
 type EventCategory int
 
 const (
@@ -37,6 +86,28 @@ func (c EventCategory) String() string {
 	}[c]
 }
 
+// machine-readable name used in query parameters and configuration
+func (c EventCategory) Key() string {
+	return []string{
+		"public",
+		"ag",
+		"exam",
+		"student",
+		"sek1",
+		"sek2",
+		"teacher",
+	}[c]
+}
+
+func ParseEventCategory(key string) (category EventCategory, err error) {
+	for c := PublicEvent; c <= TeacherEvent; c++ {
+		if c.Key() == key {
+			return c, nil
+		}
+	}
+	return category, errors.New("error: unknown event category " + key)
+}
+
 func (c EventCategory) Color() string {
 	return []string{
 		"emerald-400", // Öffentlich
@@ -0,0 +1,28 @@
+package types
+
+import "strconv"
+
+type Room struct {
+	// short name used by WebUntis
+	Name       string `json:"name"`
+	Alias      string `json:"alias"`
+	LongName   string `json:"longName"`
+	Building   string `json:"building"`
+	Floor      *int   `json:"floor"`
+	Capacity   int    `json:"capacity"`
+	Accessible bool   `json:"accessible"`
+}
+
+// get the German label of the floor, e.g. "EG" or "1. OG"
+func (room Room) FloorLabel() string {
+	if room.Floor == nil {
+		return ""
+	}
+	switch {
+	case *room.Floor == 0:
+		return "EG"
+	case *room.Floor < 0:
+		return "UG"
+	}
+	return strconv.Itoa(*room.Floor) + ". OG"
+}
@@ -1,131 +1,34 @@
 package types
 
-type Subject int
-
-const (
-	Biologie Subject = iota
-	Chemie
-	Deutsch
-	Englisch
-	Franzoesisch
-	Geographie
-	Geschichte
-	Informatik
-	Kunst
-	Latein
-	LER
-	Mathematik
-	Musik
-	PB
-	Physik
-	Recht
-	ReligionEv
-	ReligionKa
-	Seminarkurs
-	Spanisch
-	Sport
-	Technik
-	WAT
-	EmptySubject
-)
+type Subject struct {
+	Name string `json:"name"`
+	// short codes used by WebUntis, e.g. "MA"
+	Codes         []string `json:"codes"`
+	Abbreviations []string `json:"abbreviations"`
+	Synonyms      []string `json:"synonyms"`
+	Color         string   `json:"color"`
+	Icon          string   `json:"icon,omitempty"`
+}
 
 func (subject Subject) String() string {
-	return []string{
-		"Biologie",
-		"Chemie",
-		"Deutsch",
-		"Englisch",
-		"Französisch",
-		"Geographie",
-		"Geschichte",
-		"Informatik",
-		"Kunst",
-		"Latein",
-		"LER",
-		"Mathematik",
-		"Musik",
-		"PB",
-		"Physik",
-		"Recht",
-		"ev. Religion",
-		"kat. Religion",
-		"Seminarkurs",
-		"Spanisch",
-		"Sport",
-		"Technik",
-		"WAT",
-		"",
-	}[subject]
+	return subject.Name
 }
 
 func (subject Subject) Short() string {
-	return []string{
-		"BI",
-		"CH",
-		"DE",
-		"EN",
-		"FR",
-		"EK",
-		"GE",
-		"IF",
-		"KU",
-		"LA",
-		"LE",
-		"MA",
-		"MU",
-		"PB",
-		"PH",
-		"RL",
-		"RE",
-		"RK",
-		"SK",
-		"SN",
-		"SP",
-		"TE",
-		"LE",
-		"",
-	}[subject]
+	if len(subject.Codes) == 0 {
+		return ""
+	}
+	return subject.Codes[0]
 }
 
-func (subject Subject) ShortAlt() string {
-	return []string{
-		"Bio",
-		"Che",
-		"Deu",
-		"Eng",
-		"Fra",
-		"Geo",
-		"Ges",
-		"Inf",
-		"Kun",
-		"Lat",
-		"LER",
-		"Mat",
-		"Mus",
-		"PB",
-		"Phy",
-		"Rec",
-		"evR",
-		"kaR",
-		"SK",
-		"Spa",
-		"Spo",
-		"Tec",
-		"WAT",
-		"",
-	}[subject]
+func (subject Subject) IsEmpty() bool {
+	return subject.Name == ""
 }
 
 func (subject Subject) Variants() (variants []string) {
-	if subject == PB {
-		variants = append(variants, "Politische Bildung")
-		variants = append(variants, "Polit. Bildung")
-	}
-	variants = append(variants, subject.String())
-	if subject == Mathematik {
-		variants = append(variants, "Mathe")
-	}
-	variants = append(variants, subject.ShortAlt())
-	variants = append(variants, subject.Short())
+	variants = append(variants, subject.Synonyms...)
+	variants = append(variants, subject.Name)
+	variants = append(variants, subject.Abbreviations...)
+	variants = append(variants, subject.Codes...)
 	return variants
 }
@@ -0,0 +1,54 @@
+package types
+
+import "slices"
+
+type TeacherFormat string
+
+const (
+	FullNameFormat   TeacherFormat = "full"
+	SalutationFormat TeacherFormat = "salutation"
+	ShortNameFormat  TeacherFormat = "short"
+	HiddenFormat     TeacherFormat = "hidden"
+)
+
+func (format TeacherFormat) IsValid() bool {
+	return slices.Contains([]TeacherFormat{FullNameFormat, SalutationFormat, ShortNameFormat, HiddenFormat}, format)
+}
+
+type Teacher struct {
+	ShortName string `json:"shortName"`
+	LongName  string `json:"longName"`
+	// overrides for single teachers, configured in config/teachers.json
+	DisplayName string        `json:"displayName,omitempty"`
+	Salutation  string        `json:"salutation,omitempty"`
+	Format      TeacherFormat `json:"format,omitempty"`
+}
+
+// get the teacher's name in the given format, the teacher's own format takes precedence unless names are hidden
+func (teacher Teacher) Name(format TeacherFormat) string {
+	if teacher.Format != "" && format != HiddenFormat {
+		format = teacher.Format
+	}
+
+	name := teacher.DisplayName
+	if name == "" {
+		name = teacher.LongName
+	}
+	if name == "" {
+		name = teacher.ShortName
+	}
+
+	switch format {
+	case HiddenFormat:
+		return ""
+	case ShortNameFormat:
+		if teacher.ShortName != "" {
+			return teacher.ShortName
+		}
+	case SalutationFormat:
+		if teacher.Salutation != "" {
+			return teacher.Salutation + " " + name
+		}
+	}
+	return name
+}